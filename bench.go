@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"defetch/helper"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// benchRequest holds the arguments of `defetch bench disk`
+type benchRequest struct {
+	dir      string
+	size     helper.Bytes
+	duration time.Duration
+}
+
+// parseBench parses `defetch bench disk [flags] <dir>`, printing problems to
+// stderr and returning nil for them
+func parseBench(args []string) *benchRequest {
+	if len(args) == 0 || args[0] != "disk" {
+		fmt.Fprintln(os.Stderr, "usage: defetch bench disk [-size size] [-duration duration] <dir>")
+		return nil
+	}
+
+	request := &benchRequest{size: 256 << 20}
+	flags := flag.NewFlagSet("bench disk", flag.ContinueOnError)
+	flags.Var(&request.size, "size", "`size` of the test file, e.g. 1GiB")
+	flags.DurationVar(&request.duration, "duration", 2*time.Second, "time limit of each of the four tests")
+	if err := flags.Parse(args[1:]); err != nil {
+		return nil
+	}
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: defetch bench disk [-size size] [-duration duration] <dir>")
+		return nil
+	}
+	// The test file is written on the running system, not below another root
+	if *root != "" {
+		fmt.Fprintln(os.Stderr, "defetch: bench: --root cannot be combined with bench")
+		return nil
+	}
+
+	dir, err := filepath.Abs(flags.Arg(0))
+	if err == nil {
+		dir, err = filepath.EvalSymlinks(dir)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "defetch: bench: %v\n", err)
+		return nil
+	}
+	request.dir = dir
+	return request
+}
+
+// run benchmarks the directory and stores the results in the storage section
+// of sysInfo
+func (r *benchRequest) run(ctx context.Context, sysInfo interface{}) error {
+	info, ok := sysInfo.(helper.SysInfo)
+	if !ok {
+		return errors.New("not supported on this operating system")
+	}
+	// Partial failures of the storage collector still leave the disks to look at
+	if err := info.Errors[helper.SectionStorage]; err != nil && len(info.Storage) == 0 {
+		return err
+	}
+	return benchmarkDisk(ctx, info.Storage, r.dir, r.size, r.duration)
+}
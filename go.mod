@@ -3,6 +3,7 @@ module defetch
 go 1.22.5
 
 require (
+	github.com/BurntSushi/toml v1.4.0
 	github.com/shirou/gopsutil v3.21.11+incompatible
 	golang.org/x/sys v0.23.0
 )
@@ -0,0 +1,120 @@
+package helper
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Bytes is a size or amount of data in bytes
+type Bytes uint64
+
+// Units selects how Bytes are formatted for display
+type Units int
+
+const (
+	UnitsIEC   Units = iota // Powers of 1024: KiB, MiB, GiB, ...
+	UnitsSI                 // Powers of 1000: kB, MB, GB, ...
+	UnitsBytes              // Plain byte counts
+)
+
+var (
+	iecSuffixes = []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+	siSuffixes  = []string{"B", "kB", "MB", "GB", "TB", "PB", "EB"}
+)
+
+// ParseUnits converts the name of a unit system (iec, si or bytes) to Units
+func ParseUnits(name string) (Units, error) {
+	switch name {
+	case "iec":
+		return UnitsIEC, nil
+	case "si":
+		return UnitsSI, nil
+	case "bytes":
+		return UnitsBytes, nil
+	}
+	return 0, fmt.Errorf("unknown units %q, expected iec, si or bytes", name)
+}
+
+// Format renders b in the given unit system, e.g. "1.5 GiB"
+func (b Bytes) Format(units Units) string {
+	base, suffixes := 1024.0, iecSuffixes
+	switch units {
+	case UnitsBytes:
+		return strconv.FormatUint(uint64(b), 10) + " B"
+	case UnitsSI:
+		base, suffixes = 1000.0, siSuffixes
+	}
+
+	value := float64(b)
+	i := 0
+	for value >= base && i < len(suffixes)-1 {
+		value /= base
+		i++
+	}
+	if i == 0 {
+		return fmt.Sprintf("%d %s", b, suffixes[0])
+	}
+	return fmt.Sprintf("%.1f %s", value, suffixes[i])
+}
+
+// ParseBytes converts a size such as "4096", "512 KiB" or "1.5GB" to Bytes.
+// Suffixes are those of Format in either unit system, and a bare k, M, G or T
+// is taken as a power of 1024.
+func ParseBytes(value string) (Bytes, error) {
+	number := strings.TrimSpace(value)
+	suffix := strings.TrimLeft(number, "0123456789.")
+	number = strings.TrimSpace(strings.TrimSuffix(number, suffix))
+	suffix = strings.TrimSpace(suffix)
+
+	multiplier := 1.0
+	if suffix != "" && suffix != "B" {
+		found := false
+		for i := 1; i < len(iecSuffixes) && !found; i++ {
+			switch suffix {
+			case iecSuffixes[i], iecSuffixes[i][:1], iecSuffixes[i][:1] + "i":
+				multiplier, found = math.Pow(1024, float64(i)), true
+			case siSuffixes[i]:
+				multiplier, found = math.Pow(1000, float64(i)), true
+			}
+		}
+		// SI writes a lowercase k, which alone still means KiB like K
+		if !found && suffix == "k" {
+			multiplier, found = 1024, true
+		}
+		if !found {
+			return 0, fmt.Errorf("invalid size %q: unknown unit %q", value, suffix)
+		}
+	}
+
+	size, err := strconv.ParseFloat(number, 64)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("invalid size %q", value)
+	}
+	return Bytes(size * multiplier), nil
+}
+
+// Set parses value with ParseBytes, so that *Bytes can be used as a flag
+func (b *Bytes) Set(value string) error {
+	size, err := ParseBytes(value)
+	if err != nil {
+		return err
+	}
+	*b = size
+	return nil
+}
+
+// String formats b in IEC units
+func (b Bytes) String() string {
+	return b.Format(UnitsIEC)
+}
+
+// JSONSchema describes Bytes, which are always written as plain byte counts
+func (Bytes) JSONSchema() map[string]any {
+	return map[string]any{
+		"type":        "integer",
+		"minimum":     0,
+		"description": "Size in bytes",
+	}
+}
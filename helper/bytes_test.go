@@ -0,0 +1,28 @@
+package helper
+
+import "testing"
+
+func TestParseBytes(t *testing.T) {
+	tests := map[string]Bytes{
+		"4096":    4096,
+		"512 B":   512,
+		"256MiB":  256 << 20,
+		"256M":    256 << 20,
+		"1.5 GiB": 3 << 29,
+		"1GB":     1000000000,
+		"64k":     64 << 10,
+		"2Ti":     2 << 40,
+	}
+	for value, want := range tests {
+		got, err := ParseBytes(value)
+		if err != nil || got != want {
+			t.Errorf("%s: got %d, %v, want %d", value, got, err, want)
+		}
+	}
+
+	for _, value := range []string{"", "MiB", "1 XB", "-1", "1.2.3 K"} {
+		if _, err := ParseBytes(value); err == nil {
+			t.Errorf("%s: no error", value)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package helper
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Sections filled by the built-in collectors
+const (
+	SectionHost        = "host"
+	SectionCPU         = "cpu"
+	SectionGPU         = "gpu"
+	SectionMotherboard = "motherboard"
+	SectionMemory      = "memory"
+	SectionStorage     = "storage"
+	SectionNetwork     = "network"
+	SectionRouting     = "routing"
+	SectionBattery     = "battery"
+	SectionPeripherals = "peripherals"
+	SectionSoftware    = "software"
+	SectionPerformance = "performance"
+	SectionPackages    = "packages"
+	SectionOther       = "other"
+)
+
+// Collector gathers the information for one section of the report
+type Collector interface {
+	Name() string                             // Unique collector name
+	Section() string                          // Section the collected value belongs to
+	Collect(ctx context.Context) (any, error) // Gathers the section value, which may be partial when an error is returned
+}
+
+var (
+	registryMu sync.Mutex
+	registry   []Collector
+)
+
+// Register adds a collector to the registry that SelectCollectors picks from.
+// Collectors from other packages should be registered from an init function;
+// values they return for unknown sections end up in SysInfo.Extra. Register panics if the name is already taken.
+func Register(c Collector) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	for _, registered := range registry {
+		if registered.Name() == c.Name() {
+			panic(fmt.Sprintf("helper: collector %q registered twice", c.Name()))
+		}
+	}
+	registry = append(registry, c)
+}
+
+// Collectors returns the registered collectors in registration order
+func Collectors() []Collector {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	return append([]Collector(nil), registry...)
+}
+
+// SelectCollectors returns the registered collectors named in names, in that
+// order. A name may also be a section, which selects every collector of that
+// section. All collectors are returned when names is empty.
+func SelectCollectors(names []string) ([]Collector, error) {
+	registered := Collectors()
+	if len(names) == 0 {
+		return registered, nil
+	}
+
+	var selected []Collector
+	seen := make(map[string]bool)
+	for _, name := range names {
+		found := false
+		for _, c := range registered {
+			if c.Name() != name && c.Section() != name {
+				continue
+			}
+			found = true
+			if !seen[c.Name()] {
+				seen[c.Name()] = true
+				selected = append(selected, c)
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown collector or section %q", name)
+		}
+	}
+	return selected, nil
+}
+
+// NewCollector wraps a plain function into a Collector
+func NewCollector(name, section string, fn func(ctx context.Context) (any, error)) Collector {
+	return &funcCollector{name: name, section: section, fn: fn}
+}
+
+type funcCollector struct {
+	name    string
+	section string
+	fn      func(ctx context.Context) (any, error)
+}
+
+func (c *funcCollector) Name() string    { return c.name }
+func (c *funcCollector) Section() string { return c.section }
+
+func (c *funcCollector) Collect(ctx context.Context) (any, error) {
+	return c.fn(ctx)
+}
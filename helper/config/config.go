@@ -0,0 +1,135 @@
+// Package config loads the defetch configuration file.
+//
+// The file is TOML and lives at $XDG_CONFIG_HOME/defetch/config.toml unless
+// another path is given. Every key is optional:
+//
+//	format   = "text"              # text, json, logo or an inline template
+//	units    = "iec"               # iec, si or bytes
+//	template = "~/layout.tmpl"     # text/template layout for text output
+//	logo     = "~/logo.txt"        # custom ASCII art for logo output
+//	colors   = ["blue", "white"]   # logo palette, overriding the distribution's
+//	color    = "auto"              # auto, always or never
+//	workers  = 4
+//	timeout  = "10s"               # default deadline of each collector
+//	strict   = false
+//	timings  = false
+//	sections = ["host", "cpu", "memory"]  # collectors or sections to run, in output order
+//
+//	[collectors.software]
+//	timeout   = "20s"              # overrides the default deadline
+//	processes = 10                 # any other key is passed to the collector
+package config
+
+import (
+	"defetch/helper"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Config is the content of the configuration file. Zero values mean the key
+// was not set.
+type Config struct {
+	Format     string                     `toml:"format"`
+	Units      string                     `toml:"units"`
+	Template   string                     `toml:"template"`
+	Logo       string                     `toml:"logo"`
+	Colors     []string                   `toml:"colors"`
+	Color      string                     `toml:"color"`
+	Workers    int                        `toml:"workers"`
+	Timeout    string                     `toml:"timeout"`
+	Strict     *bool                      `toml:"strict"`
+	Timings    *bool                      `toml:"timings"`
+	Sections   []string                   `toml:"sections"`
+	Collectors map[string]helper.Settings `toml:"collectors"`
+}
+
+// DefaultPath returns $XDG_CONFIG_HOME/defetch/config.toml, or the platform's
+// equivalent when XDG_CONFIG_HOME is not set
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "defetch", "config.toml"), nil
+}
+
+// Load reads the configuration file at path. When path is empty the default
+// file is read, and an empty Config is returned if it does not exist.
+func Load(path string) (Config, error) {
+	var cfg Config
+
+	explicit := path != ""
+	if !explicit {
+		var err error
+		if path, err = DefaultPath(); err != nil {
+			return cfg, nil
+		}
+	}
+
+	meta, err := toml.DecodeFile(path, &cfg)
+	if err != nil {
+		if !explicit && errors.Is(err, fs.ErrNotExist) {
+			return Config{}, nil
+		}
+		return Config{}, err
+	}
+	if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+		return Config{}, fmt.Errorf("%s: unknown key %q", path, undecoded[0].String())
+	}
+
+	// Paths in the file may be relative to the home directory
+	cfg.Template = expandHome(cfg.Template)
+	cfg.Logo = expandHome(cfg.Logo)
+
+	return cfg, nil
+}
+
+// RunOptions returns the per-collector deadlines and settings from the
+// [collectors.<name>] tables. Tables must be named after a registered collector.
+func (c Config) RunOptions() (timeouts map[string]time.Duration, settings map[string]helper.Settings, err error) {
+	registered := make(map[string]bool)
+	for _, collector := range helper.Collectors() {
+		registered[collector.Name()] = true
+	}
+
+	for name, collector := range c.Collectors {
+		if !registered[name] {
+			return nil, nil, fmt.Errorf("collectors.%s: unknown collector", name)
+		}
+		if _, ok := collector["timeout"]; ok {
+			timeout, err := collector.Duration("timeout", 0)
+			if err != nil {
+				return nil, nil, fmt.Errorf("collectors.%s.%w", name, err)
+			}
+			if timeouts == nil {
+				timeouts = make(map[string]time.Duration)
+			}
+			timeouts[name] = timeout
+		}
+		if settings == nil {
+			settings = make(map[string]helper.Settings)
+		}
+		settings[name] = collector
+	}
+	return timeouts, settings, nil
+}
+
+// expandHome replaces a leading ~ with the home directory
+func expandHome(path string) string {
+	rest, ok := strings.CutPrefix(path, "~")
+	if !ok || (rest != "" && rest[0] != '/' && rest[0] != filepath.Separator) {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, rest)
+}
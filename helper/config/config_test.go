@@ -0,0 +1,134 @@
+package config
+
+import (
+	"context"
+	"defetch/helper"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func init() {
+	helper.Register(helper.NewCollector("software", helper.SectionSoftware, func(ctx context.Context) (any, error) {
+		return nil, nil
+	}))
+}
+
+// Helper function to write content to a config file in a temporary directory
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	path := writeConfig(t, `
+format   = "logo"
+template = "~/layout.tmpl"
+workers  = 2
+strict   = true
+sections = ["host", "cpu"]
+
+[collectors.software]
+timeout   = "20s"
+processes = 10
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	strict := true
+	want := Config{
+		Format:     "logo",
+		Template:   filepath.Join(home, "layout.tmpl"),
+		Workers:    2,
+		Strict:     &strict,
+		Sections:   []string{"host", "cpu"},
+		Collectors: map[string]helper.Settings{"software": {"timeout": "20s", "processes": int64(10)}},
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("got %+v, want %+v", cfg, want)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	// Misspelt keys are reported instead of being ignored
+	if _, err := Load(writeConfig(t, "fromat = \"json\"\n")); err == nil {
+		t.Error("got no error for an unknown key")
+	}
+	if _, err := Load(writeConfig(t, "workers = \"four\"\n")); err == nil {
+		t.Error("got no error for a value of the wrong type")
+	}
+	// A file given explicitly must exist
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.toml")); err == nil {
+		t.Error("got no error for a missing file")
+	}
+}
+
+func TestLoadDefault(t *testing.T) {
+	// Without a file at the default path every key is unset
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cfg, Config{}) {
+		t.Errorf("got %+v, want an empty config", cfg)
+	}
+}
+
+func TestRunOptions(t *testing.T) {
+	cfg := Config{Collectors: map[string]helper.Settings{
+		"software": {"timeout": "20s", "processes": int64(10)},
+	}}
+	timeouts, settings, err := cfg.RunOptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]time.Duration{"software": 20 * time.Second}; !reflect.DeepEqual(timeouts, want) {
+		t.Errorf("got timeouts %v, want %v", timeouts, want)
+	}
+	if !reflect.DeepEqual(settings, cfg.Collectors) {
+		t.Errorf("got settings %v, want %v", settings, cfg.Collectors)
+	}
+}
+
+func TestRunOptionsErrors(t *testing.T) {
+	tests := map[string]Config{
+		// Tables are named after collectors, not sections
+		"unknown collector": {Collectors: map[string]helper.Settings{"softwar": {}}},
+		"section":           {Collectors: map[string]helper.Settings{helper.SectionStorage: {}}},
+		"bad timeout":       {Collectors: map[string]helper.Settings{"software": {"timeout": "soon"}}},
+	}
+	for name, cfg := range tests {
+		if _, _, err := cfg.RunOptions(); err == nil {
+			t.Errorf("%s: got no error", name)
+		}
+	}
+}
+
+func TestExpandHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	tests := map[string]string{
+		"~":             home,
+		"~/layout.tmpl": filepath.Join(home, "layout.tmpl"),
+		"/etc/defetch":  "/etc/defetch",
+		"layout.tmpl":   "layout.tmpl",
+		"~other/a.tmpl": "~other/a.tmpl",
+		"":              "",
+	}
+	for path, want := range tests {
+		if got := expandHome(path); got != want {
+			t.Errorf("%q: got %q, want %q", path, got, want)
+		}
+	}
+}
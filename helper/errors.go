@@ -0,0 +1,85 @@
+package helper
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ErrToolMissing is matched by errors from commands that are not installed
+var ErrToolMissing = errors.New("required tool is not installed")
+
+// CommandError reports an external command that could not be run or failed
+type CommandError struct {
+	Command string // Command line that was run
+	Err     error  // Underlying error from os/exec
+}
+
+func (e *CommandError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Command, e.Err)
+}
+
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports missing executables as ErrToolMissing
+func (e *CommandError) Is(target error) bool {
+	return target == ErrToolMissing && errors.Is(e.Err, exec.ErrNotFound)
+}
+
+// NewCommandError wraps err with the command line that produced it
+func NewCommandError(name string, args []string, err error) *CommandError {
+	return &CommandError{
+		Command: strings.Join(append([]string{name}, args...), " "),
+		Err:     err,
+	}
+}
+
+// SectionError records why a section of the report is missing or incomplete
+type SectionError struct {
+	Section   string // Section the collector fills
+	Collector string // Name of the failing collector
+	Err       error  // What went wrong
+}
+
+func (e *SectionError) Error() string {
+	return fmt.Sprintf("%s (%s): %v", e.Section, e.Collector, e.Err)
+}
+
+func (e *SectionError) Unwrap() error {
+	return e.Err
+}
+
+// MarshalJSON writes the error message instead of the opaque error value
+func (e *SectionError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Section     string `json:"section"`
+		Collector   string `json:"collector"`
+		Message     string `json:"message"`
+		ToolMissing bool   `json:"tool_missing"`
+		TimedOut    bool   `json:"timed_out"`
+	}{
+		Section:     e.Section,
+		Collector:   e.Collector,
+		Message:     e.Err.Error(),
+		ToolMissing: errors.Is(e.Err, ErrToolMissing),
+		TimedOut:    errors.Is(e.Err, ErrTimeout),
+	})
+}
+
+// AddSectionError records err in errs under the collector's section, joining
+// it with any error already reported for the same section
+func AddSectionError(errs map[string]*SectionError, collector Collector, err error) {
+	if prev, ok := errs[collector.Section()]; ok {
+		prev.Err = errors.Join(prev.Err, err)
+		return
+	}
+	errs[collector.Section()] = &SectionError{
+		Section:   collector.Section(),
+		Collector: collector.Name(),
+		Err:       err,
+	}
+}
@@ -0,0 +1,113 @@
+package helper
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// RootFS returns the file system below dir, e.g. a mounted disk image or the
+// root of a container. Unlike os.DirFS it can read symbolic links, which sysfs
+// is full of, and its errors carry the real path of the file. Symbolic links
+// are resolved by the operating system, so absolute links inside dir point
+// outside of it.
+func RootFS(dir string) fs.FS {
+	return rootFS(filepath.Clean(dir))
+}
+
+// liveFS is the root of the running system
+var liveFS = RootFS("/")
+
+// IsLive reports whether fsys is the root of the running system. Only then do
+// commands and system calls describe the same system as the files collectors
+// read.
+func IsLive(fsys fs.FS) bool {
+	return fsys == liveFS
+}
+
+type fsKey struct{}
+
+// withFS returns a context carrying the file system collectors run with it read from
+func withFS(ctx context.Context, fsys fs.FS) context.Context {
+	if fsys == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, fsKey{}, fsys)
+}
+
+// CollectorFS returns the file system the collector running with ctx reads
+// from: RunOptions.FS, or the root of the running system when it is not set.
+// Paths are unrooted, e.g. "proc/cpuinfo".
+func CollectorFS(ctx context.Context) fs.FS {
+	if fsys, ok := ctx.Value(fsKey{}).(fs.FS); ok {
+		return fsys
+	}
+	return liveFS
+}
+
+// ReadLinkFS is implemented by file systems that can read symbolic links, like
+// those returned by RootFS
+type ReadLinkFS interface {
+	fs.FS
+	ReadLink(name string) (string, error)
+}
+
+// ReadLink returns the target of the symbolic link name in fsys
+func ReadLink(fsys fs.FS, name string) (string, error) {
+	linkFS, ok := fsys.(ReadLinkFS)
+	if !ok {
+		return "", &fs.PathError{Op: "readlink", Path: name, Err: fs.ErrInvalid}
+	}
+	return linkFS.ReadLink(name)
+}
+
+type rootFS string
+
+// path returns the real path of name, which must be a valid fs.FS path
+func (r rootFS) path(op, name string) (string, error) {
+	if !fs.ValidPath(name) {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	return filepath.Join(string(r), filepath.FromSlash(name)), nil
+}
+
+func (r rootFS) Open(name string) (fs.File, error) {
+	path, err := r.path("open", name)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+func (r rootFS) ReadFile(name string) ([]byte, error) {
+	path, err := r.path("open", name)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(path)
+}
+
+func (r rootFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	path, err := r.path("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadDir(path)
+}
+
+func (r rootFS) Stat(name string) (fs.FileInfo, error) {
+	path, err := r.path("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return os.Stat(path)
+}
+
+func (r rootFS) ReadLink(name string) (string, error) {
+	path, err := r.path("readlink", name)
+	if err != nil {
+		return "", err
+	}
+	return os.Readlink(path)
+}
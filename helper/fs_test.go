@@ -0,0 +1,45 @@
+package helper
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestRootFS(t *testing.T) {
+	if err := fstest.TestFS(RootFS("testdata"), "schema/v1.json"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRootFSReadLink(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Symlink("../../devices/card0", filepath.Join(dir, "card0")); err != nil {
+		t.Fatal(err)
+	}
+
+	fsys := RootFS(dir)
+	target, err := ReadLink(fsys, "card0")
+	if err != nil || target != "../../devices/card0" {
+		t.Errorf("ReadLink = %q, %v", target, err)
+	}
+	// Paths are unrooted like those of every fs.FS
+	if _, err := ReadLink(fsys, "/card0"); !errors.Is(err, fs.ErrInvalid) {
+		t.Errorf("ReadLink of a rooted path: got %v, want fs.ErrInvalid", err)
+	}
+	if _, err := fs.ReadFile(fsys, "../etc/passwd"); !errors.Is(err, fs.ErrInvalid) {
+		t.Errorf("reading outside the root: got %v, want fs.ErrInvalid", err)
+	}
+}
+
+func TestIsLive(t *testing.T) {
+	if !IsLive(RootFS("/")) || !IsLive(RootFS("/.")) {
+		t.Error("the root of the running system is not live")
+	}
+	if IsLive(RootFS("testdata")) || IsLive(fstest.MapFS{}) {
+		t.Error("another root is live")
+	}
+}
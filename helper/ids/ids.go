@@ -0,0 +1,261 @@
+// Package ids resolves numeric PCI and USB IDs to vendor, device and class
+// names using the pci.ids and usb.ids databases.
+//
+// The copies in /usr/share/hwdata or /usr/share/misc of the system described
+// are preferred and a small embedded subset is used when neither is installed.
+// The databases of the running system are only read the first time they are
+// used.
+package ids
+
+import (
+	"bufio"
+	"defetch/helper"
+	"embed"
+	"io"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//go:embed data/*.ids
+var fallback embed.FS
+
+// Dirs lists the directories searched for pci.ids and usb.ids, in order
+var Dirs = []string{"/usr/share/hwdata", "/usr/share/misc"}
+
+// Database holds the contents of a pci.ids or usb.ids file
+type Database struct {
+	Source  string // Path the database was read from, or "embedded"
+	vendors map[uint16]*vendor
+	classes map[uint8]*class
+}
+
+type vendor struct {
+	name    string
+	devices map[uint16]*device
+}
+
+type device struct {
+	name       string
+	subsystems map[uint32]string // Keyed by subsystem vendor << 16 | subsystem device
+}
+
+type class struct {
+	name       string
+	subclasses map[uint8]string
+}
+
+var (
+	pciOnce, usbOnce sync.Once
+	pciDB, usbDB     *Database
+)
+
+// PCI returns the PCI ID database of the system whose root is fsys, such as
+// helper.CollectorFS returns. That of the running system is loaded on first use.
+func PCI(fsys fs.FS) *Database {
+	if !helper.IsLive(fsys) {
+		return Load(fsys, "pci.ids")
+	}
+	pciOnce.Do(func() { pciDB = Load(fsys, "pci.ids") })
+	return pciDB
+}
+
+// USB returns the USB ID database of the system whose root is fsys, such as
+// helper.CollectorFS returns. That of the running system is loaded on first use.
+func USB(fsys fs.FS) *Database {
+	if !helper.IsLive(fsys) {
+		return Load(fsys, "usb.ids")
+	}
+	usbOnce.Do(func() { usbDB = Load(fsys, "usb.ids") })
+	return usbDB
+}
+
+// Load reads the first copy of name found in Dirs below fsys, falling back on
+// the embedded one
+func Load(fsys fs.FS, name string) *Database {
+	for _, dir := range Dirs {
+		file, err := fsys.Open(path.Join(strings.TrimPrefix(dir, "/"), name))
+		if err != nil {
+			continue
+		}
+		db, err := Parse(file)
+		file.Close()
+		if err == nil {
+			db.Source = path.Join(dir, name)
+			return db
+		}
+	}
+
+	file, err := fallback.Open("data/" + name)
+	if err != nil {
+		return &Database{Source: "embedded"}
+	}
+	defer file.Close()
+	db, err := Parse(file)
+	if err != nil {
+		return &Database{Source: "embedded"}
+	}
+	db.Source = "embedded"
+	return db
+}
+
+// Parse reads a database in the pci.ids/usb.ids format. Sections other than
+// vendors and device classes, such as the HID tables of usb.ids, are skipped.
+func Parse(r io.Reader) (*Database, error) {
+	db := &Database{
+		vendors: make(map[uint16]*vendor),
+		classes: make(map[uint8]*class),
+	}
+
+	var currentVendor *vendor
+	var currentDevice *device
+	var currentClass *class
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "\t\t"):
+			// Subsystems of a PCI device; interfaces of a USB device are ignored
+			if currentDevice == nil {
+				continue
+			}
+			id, name, ok := cutEntry(line[2:])
+			subVendor, subDevice, found := strings.Cut(id, " ")
+			if !ok || !found {
+				continue
+			}
+			sv, err1 := parseHex(subVendor, 16)
+			sd, err2 := parseHex(subDevice, 16)
+			if err1 == nil && err2 == nil {
+				if currentDevice.subsystems == nil {
+					currentDevice.subsystems = make(map[uint32]string)
+				}
+				currentDevice.subsystems[uint32(sv)<<16|uint32(sd)] = name
+			}
+
+		case strings.HasPrefix(line, "\t"):
+			id, name, ok := cutEntry(line[1:])
+			if !ok {
+				continue
+			}
+			switch {
+			case currentVendor != nil:
+				if deviceID, err := parseHex(id, 16); err == nil {
+					currentDevice = &device{name: name}
+					currentVendor.devices[uint16(deviceID)] = currentDevice
+				}
+			case currentClass != nil:
+				if subclass, err := parseHex(id, 8); err == nil {
+					currentClass.subclasses[uint8(subclass)] = name
+				}
+			}
+
+		default:
+			currentVendor, currentDevice, currentClass = nil, nil, nil
+			if rest, ok := strings.CutPrefix(line, "C "); ok {
+				id, name, ok := cutEntry(rest)
+				if classID, err := parseHex(id, 8); ok && err == nil {
+					currentClass = &class{name: name, subclasses: make(map[uint8]string)}
+					db.classes[uint8(classID)] = currentClass
+				}
+				continue
+			}
+			// Other sections like "AT" or "HID" start with a keyword instead of an ID
+			id, name, ok := cutEntry(line)
+			if vendorID, err := parseHex(id, 16); ok && err == nil {
+				currentVendor = &vendor{name: name, devices: make(map[uint16]*device)}
+				db.vendors[uint16(vendorID)] = currentVendor
+			}
+		}
+	}
+
+	return db, scanner.Err()
+}
+
+// Vendor returns the name of a vendor given as hex, e.g. "10de"
+func (db *Database) Vendor(vendorID string) string {
+	if v := db.vendor(vendorID); v != nil {
+		return v.name
+	}
+	return ""
+}
+
+// Device returns the name of a vendor's device
+func (db *Database) Device(vendorID, deviceID string) string {
+	if d := db.device(vendorID, deviceID); d != nil {
+		return d.name
+	}
+	return ""
+}
+
+// Subsystem returns the name a board maker gave a PCI device
+func (db *Database) Subsystem(vendorID, deviceID, subVendorID, subDeviceID string) string {
+	d := db.device(vendorID, deviceID)
+	sv, err1 := parseHex(subVendorID, 16)
+	sd, err2 := parseHex(subDeviceID, 16)
+	if d == nil || err1 != nil || err2 != nil {
+		return ""
+	}
+	return d.subsystems[uint32(sv)<<16|uint32(sd)]
+}
+
+// Class returns the name of a device class given as hex, e.g. "09"
+func (db *Database) Class(classID string) string {
+	if c := db.class(classID); c != nil {
+		return c.name
+	}
+	return ""
+}
+
+// Subclass returns the name of a subclass of a device class
+func (db *Database) Subclass(classID, subclassID string) string {
+	c := db.class(classID)
+	id, err := parseHex(subclassID, 8)
+	if c == nil || err != nil {
+		return ""
+	}
+	return c.subclasses[uint8(id)]
+}
+
+func (db *Database) vendor(vendorID string) *vendor {
+	id, err := parseHex(vendorID, 16)
+	if err != nil {
+		return nil
+	}
+	return db.vendors[uint16(id)]
+}
+
+func (db *Database) device(vendorID, deviceID string) *device {
+	v := db.vendor(vendorID)
+	id, err := parseHex(deviceID, 16)
+	if v == nil || err != nil {
+		return nil
+	}
+	return v.devices[uint16(id)]
+}
+
+func (db *Database) class(classID string) *class {
+	id, err := parseHex(classID, 8)
+	if err != nil {
+		return nil
+	}
+	return db.classes[uint8(id)]
+}
+
+// cutEntry splits an entry into its ID and name, which are separated by two spaces
+func cutEntry(line string) (id, name string, ok bool) {
+	id, name, ok = strings.Cut(line, "  ")
+	return strings.TrimSpace(id), strings.TrimSpace(name), ok
+}
+
+// parseHex parses an ID such as "10de" or "0x10de"
+func parseHex(value string, bits int) (uint64, error) {
+	return strconv.ParseUint(strings.TrimPrefix(strings.TrimSpace(value), "0x"), 16, bits)
+}
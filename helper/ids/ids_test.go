@@ -0,0 +1,59 @@
+package ids
+
+import (
+	"defetch/helper"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLookup(t *testing.T) {
+	// pci.ids is installed in /usr/share/hwdata and usb.ids in /usr/share/misc
+	fsys := helper.RootFS("testdata")
+
+	pci := PCI(fsys)
+	if pci.Source != "/usr/share/hwdata/pci.ids" {
+		t.Errorf("got PCI source %q", pci.Source)
+	}
+	tests := []struct {
+		got, want string
+	}{
+		{pci.Vendor("8086"), "Intel Corporation"},
+		{pci.Vendor("0x8086"), "Intel Corporation"},
+		{pci.Device("8086", "9a49"), "TigerLake-LP GT2 [Iris Xe Graphics]"},
+		{pci.Subsystem("8086", "9a49", "17aa", "22d8"), "ThinkPad X1 Carbon Gen 9"},
+		{pci.Class("03"), "Display controller"},
+		{pci.Subclass("03", "00"), "VGA compatible controller"},
+		{pci.Device("8086", "ffff"), ""},
+		{pci.Vendor("10de"), ""},
+	}
+
+	usb := USB(fsys)
+	if usb.Source != "/usr/share/misc/usb.ids" {
+		t.Errorf("got USB source %q", usb.Source)
+	}
+	tests = append(tests, []struct {
+		got, want string
+	}{
+		{usb.Vendor("046d"), "Logitech, Inc."},
+		{usb.Device("046d", "c52b"), "Unifying Receiver"},
+		{usb.Class("03"), "Human Interface Device"},
+		{usb.Subclass("03", "01"), "Boot Interface Subclass"},
+	}...)
+
+	for i, test := range tests {
+		if test.got != test.want {
+			t.Errorf("%d: got %q, want %q", i, test.got, test.want)
+		}
+	}
+}
+
+func TestLookupEmbedded(t *testing.T) {
+	// Systems without the databases get the embedded subset
+	pci := PCI(fstest.MapFS{})
+	if pci.Source != "embedded" {
+		t.Errorf("got PCI source %q", pci.Source)
+	}
+	if got := pci.Vendor("10de"); got != "NVIDIA Corporation" {
+		t.Errorf("got vendor %q, want %q", got, "NVIDIA Corporation")
+	}
+}
@@ -0,0 +1,108 @@
+package helper
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// SchemaVersion is the version of the JSON document written by WriteJSON.
+// It is bumped whenever a field is added, renamed, removed or changes type,
+// so a document always validates against the schema of its own version as
+// printed by `defetch schema`.
+const SchemaVersion = 1
+
+// WriteJSON writes v as an indented JSON document. Empty strings are written
+// as null so that consumers can tell missing values from collected ones.
+func WriteJSON(w io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	data, err = nullEmptyStrings(data)
+	if err != nil {
+		return err
+	}
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, data, "", "  "); err != nil {
+		return err
+	}
+	out.WriteByte('\n')
+
+	_, err = out.WriteTo(w)
+	return err
+}
+
+// nullEmptyStrings re-encodes a JSON document with every empty string value
+// replaced by null, keeping object keys and their order untouched
+func nullEmptyStrings(data []byte) ([]byte, error) {
+	type container struct {
+		object bool // Inside an object rather than an array
+		tokens int  // Keys and values seen so far
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var out bytes.Buffer
+	var stack []container
+	for {
+		token, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// Write the separator and work out whether the token is an object key
+		isKey := false
+		if delim, ok := token.(json.Delim); len(stack) > 0 && (!ok || delim == '{' || delim == '[') {
+			top := &stack[len(stack)-1]
+			isKey = top.object && top.tokens%2 == 0
+			if top.tokens > 0 {
+				if top.object && !isKey {
+					out.WriteByte(':')
+				} else {
+					out.WriteByte(',')
+				}
+			}
+			top.tokens++
+		}
+
+		switch token := token.(type) {
+		case json.Delim:
+			out.WriteRune(rune(token))
+			switch token {
+			case '{', '[':
+				stack = append(stack, container{object: token == '{'})
+			default:
+				stack = stack[:len(stack)-1]
+			}
+		case string:
+			if token == "" && !isKey {
+				out.WriteString("null")
+				continue
+			}
+			encoded, err := json.Marshal(token)
+			if err != nil {
+				return nil, err
+			}
+			out.Write(encoded)
+		case json.Number:
+			out.WriteString(token.String())
+		case bool:
+			if token {
+				out.WriteString("true")
+			} else {
+				out.WriteString("false")
+			}
+		case nil:
+			out.WriteString("null")
+		}
+	}
+
+	return out.Bytes(), nil
+}
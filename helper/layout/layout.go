@@ -0,0 +1,169 @@
+// Package layout renders collected system information through Go
+// text/template layouts, so users can choose which fields are printed and in
+// what order.
+package layout
+
+import (
+	"defetch/helper"
+	"defetch/helper/logo"
+	"defetch/helper/windows"
+	"embed"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+)
+
+//go:embed templates/*.tmpl
+var templateFiles embed.FS
+
+// Options controls how template functions format values
+type Options struct {
+	Units helper.Units // Units used by the bytes function
+	Color bool         // Whether the color function writes escape sequences
+}
+
+// Builtin returns the default layout for the platform sysInfo was collected on.
+// It prints the given sections in order, followed by values of other
+// collectors and the errors, and reproduces the plain text output of defetch.
+func Builtin(sysInfo any, sections []string) (string, error) {
+	var name string
+	switch sysInfo.(type) {
+	case helper.SysInfo:
+		name = "linux"
+	case windows.SysInfo:
+		name = "windows"
+	default:
+		return "", fmt.Errorf("no built-in layout for %T", sysInfo)
+	}
+
+	data, err := templateFiles.ReadFile("templates/" + name + ".tmpl")
+	if err != nil {
+		return "", err
+	}
+
+	// The file only defines a template per section, so add the body using them
+	defined, err := template.New(name).Funcs(funcs(Options{}, nil)).Parse(string(data))
+	if err != nil {
+		return "", err
+	}
+	var layout strings.Builder
+	layout.Write(data)
+	for _, section := range sections {
+		if defined.Lookup(section) != nil {
+			fmt.Fprintf(&layout, "{{template %q .}}", section)
+		}
+	}
+	layout.WriteString(`{{template "footer" .}}`)
+
+	return layout.String(), nil
+}
+
+// Render executes the layout text with sysInfo as its data
+func Render(w io.Writer, text string, sysInfo any, opts Options) error {
+	var errs map[string]*helper.SectionError
+	switch sysInfo := sysInfo.(type) {
+	case helper.SysInfo:
+		errs = sysInfo.Errors
+	case windows.SysInfo:
+		errs = sysInfo.Errors
+	}
+
+	tmpl, err := template.New("layout").Funcs(funcs(opts, errs)).Parse(text)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(w, sysInfo)
+}
+
+// funcs returns the helper functions available to layouts:
+//
+//	bytes SIZE            size in the selected units, e.g. "1.5 GiB"
+//	color NAME TEXT       TEXT drawn in a colour such as "red" or "bright-blue"
+//	pad WIDTH TEXT        TEXT padded with spaces on the right to WIDTH
+//	padLeft WIDTH TEXT    TEXT padded with spaces on the left to WIDTH
+//	percent PART TOTAL    PART as a percentage of TOTAL
+//	bar WIDTH PERCENT     a bar such as "[#####-----]" filled to PERCENT
+//	default FALLBACK TEXT FALLBACK when TEXT is empty
+//	temperature CELSIUS   a sensor reading, "Unavailable" when missing
+//	collected SECTION     whether SECTION was collected without errors
+//	join SEP LIST         the strings of LIST separated by SEP
+//	tree DEVICES          stacked block devices in tree order, each with the
+//	                      branches to draw before it as .Prefix
+func funcs(opts Options, errs map[string]*helper.SectionError) template.FuncMap {
+	return template.FuncMap{
+		"bytes": func(size helper.Bytes) string {
+			return size.Format(opts.Units)
+		},
+		"color": func(name, text string) (string, error) {
+			c, err := logo.ParseColor(name)
+			if err != nil || !opts.Color {
+				return text, err
+			}
+			return c.Paint(text), nil
+		},
+		"pad": func(width int, text string) string {
+			return text + strings.Repeat(" ", max(width-utf8.RuneCountInString(text), 0))
+		},
+		"padLeft": func(width int, text string) string {
+			return strings.Repeat(" ", max(width-utf8.RuneCountInString(text), 0)) + text
+		},
+		"percent": func(part, total helper.Bytes) float64 {
+			if total == 0 {
+				return 0
+			}
+			return float64(part) / float64(total) * 100
+		},
+		"bar": func(width int, percent float64) string {
+			filled := int(math.Round(math.Max(0, math.Min(percent, 100)) / 100 * float64(width)))
+			return "[" + strings.Repeat("#", filled) + strings.Repeat("-", width-filled) + "]"
+		},
+		"default": func(fallback, text string) string {
+			if text == "" {
+				return fallback
+			}
+			return text
+		},
+		"temperature": func(celsius *float64) string {
+			if celsius == nil {
+				return "Unavailable"
+			}
+			return fmt.Sprintf("%.2f°C", *celsius)
+		},
+		"collected": func(section string) bool {
+			return errs[section] == nil
+		},
+		"join": func(sep string, list []string) string {
+			return strings.Join(list, sep)
+		},
+		"tree": tree,
+	}
+}
+
+// TreeLine is a block device of a stack with the branches drawn before it,
+// such as "│   └── "
+type TreeLine struct {
+	Prefix string
+	helper.BlockDeviceInfo
+}
+
+// tree flattens stacked block devices into lines, each device followed by the
+// devices stacked on it
+func tree(devices []helper.BlockDeviceInfo) []TreeLine {
+	var lines []TreeLine
+	var walk func(devices []helper.BlockDeviceInfo, indent string)
+	walk = func(devices []helper.BlockDeviceInfo, indent string) {
+		for i, device := range devices {
+			branch, next := "├── ", "│   "
+			if i == len(devices)-1 {
+				branch, next = "└── ", "    "
+			}
+			lines = append(lines, TreeLine{Prefix: indent + branch, BlockDeviceInfo: device})
+			walk(device.Holders, indent+next)
+		}
+	}
+	walk(devices, "")
+	return lines
+}
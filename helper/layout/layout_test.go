@@ -0,0 +1,146 @@
+package layout
+
+import (
+	"defetch/helper"
+	"defetch/helper/windows"
+	"strings"
+	"testing"
+)
+
+// sysInfo is the fixed report the layouts are rendered against
+var sysInfo = helper.SysInfo{
+	HostInfo: helper.HostInfo{Hostname: "laptop", OSName: "debian", OSVersion: "12", OSCodename: "bookworm", OSIDLike: []string{"ubuntu", "debian"}},
+	Memory:   helper.MemoryInfo{TotalSize: 16 << 30, UsedSize: 4 << 30},
+	Storage: []helper.StorageInfo{{
+		Device:   "sda",
+		Capacity: 1 << 40,
+		Partitions: []helper.PartitionInfo{{
+			Device: "sda2",
+			Size:   512 << 30,
+			Holders: []helper.BlockDeviceInfo{{
+				Device: "dm-0", Name: "cryptroot", Type: "crypt", Encryption: "LUKS2", Size: 512 << 30,
+				Holders: []helper.BlockDeviceInfo{
+					{Device: "dm-1", Name: "vg0-root", Type: "lvm", VolumeGroup: "vg0", LogicalVolume: "root", Size: 50 << 30, Filesystem: "ext4"},
+					{Device: "dm-2", Name: "vg0-swap", Type: "lvm", VolumeGroup: "vg0", LogicalVolume: "swap", Size: 16 << 30, Filesystem: "swap"},
+				},
+			}},
+		}},
+	}},
+	Errors: map[string]*helper.SectionError{
+		helper.SectionGPU: {Section: helper.SectionGPU, Collector: "gpu", Err: helper.ErrTimeout},
+	},
+}
+
+// Helper function to render text against sysInfo
+func render(t *testing.T, text string, opts Options) string {
+	t.Helper()
+	var out strings.Builder
+	if err := Render(&out, text, sysInfo, opts); err != nil {
+		t.Fatalf("%s: %v", text, err)
+	}
+	return out.String()
+}
+
+func TestFuncs(t *testing.T) {
+	tests := []struct {
+		text, want string
+	}{
+		{`{{bytes .Memory.TotalSize}}`, "16.0 GiB"},
+		{`{{color "red" .Hostname}}`, "laptop"},
+		{`[{{pad 8 .Hostname}}]`, "[laptop  ]"},
+		{`[{{padLeft 8 .Hostname}}]`, "[  laptop]"},
+		// Text longer than the width is left as it is
+		{`[{{pad 3 .Hostname}}|{{padLeft 3 .Hostname}}]`, "[laptop|laptop]"},
+		{`{{percent .Memory.UsedSize .Memory.TotalSize}}`, "25"},
+		// A total of zero gives 0 rather than NaN
+		{`{{percent .Memory.UsedSize .Memory.FreeSize}}`, "0"},
+		{`{{bar 10 25.0}}`, "[###-------]"},
+		// Values outside 0-100 fill the bar completely or not at all
+		{`{{bar 10 150.0}}`, "[##########]"},
+		{`{{bar 10 -5.0}}`, "[----------]"},
+		{`{{default "Unknown" .OSCodename}}`, "bookworm"},
+		{`{{default "Unknown" .CurrentUser}}`, "Unknown"},
+		{`{{temperature .OtherInfo.Temperature.CPU}}`, "Unavailable"},
+		{`{{collected "host"}} {{collected "gpu"}}`, "true false"},
+		{`{{join ", " .OSIDLike}}`, "ubuntu, debian"},
+		{`{{range tree (index (index .Storage 0).Partitions 0).Holders}}{{.Prefix}}{{.Device}};{{end}}`, "└── dm-0;    ├── dm-1;    └── dm-2;"},
+	}
+	for _, test := range tests {
+		if got := render(t, test.text, Options{}); got != test.want {
+			t.Errorf("%s: got %q, want %q", test.text, got, test.want)
+		}
+	}
+
+	// Colours are only drawn when asked for, and unknown ones are errors
+	if got := render(t, `{{color "red" "x"}}`, Options{Color: true}); got == "x" || !strings.Contains(got, "x") {
+		t.Errorf("got %q, want x in red", got)
+	}
+	if err := Render(&strings.Builder{}, `{{color "mauve" "x"}}`, sysInfo, Options{}); err == nil {
+		t.Error("got no error for an unknown colour")
+	}
+}
+
+func TestTree(t *testing.T) {
+	// Branches continue with │ below devices that have siblings after them
+	devices := []helper.BlockDeviceInfo{
+		{Device: "md0", Holders: []helper.BlockDeviceInfo{{Device: "dm-0"}}},
+		{Device: "md1"},
+	}
+	lines := tree(devices)
+	want := []string{"├── md0", "│   └── dm-0", "└── md1"}
+	if len(lines) != len(want) {
+		t.Fatalf("got %d lines, want %d", len(lines), len(want))
+	}
+	for i, line := range lines {
+		if got := line.Prefix + line.Device; got != want[i] {
+			t.Errorf("%d: got %q, want %q", i, got, want[i])
+		}
+	}
+}
+
+func TestBuiltin(t *testing.T) {
+	text, err := Builtin(sysInfo, []string{helper.SectionHost, helper.SectionStorage, "unknown"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := render(t, text, Options{})
+
+	for _, want := range []string{
+		"Hostname: laptop\n",
+		"Operating System: debian 12 (bookworm)\n",
+		"Shell Version: Unknown\n",
+		"Device: sda\nModel: Unknown\nCapacity: 1.0 TiB\n",
+		"Partition: sda2, 512.0 GiB\n" +
+			"└── dm-0 (cryptroot): crypt LUKS2, 512.0 GiB\n" +
+			"    ├── dm-1 (vg0-root): lvm vg0/root, 50.0 GiB, ext4\n" +
+			"    └── dm-2 (vg0-swap): lvm vg0/swap, 16.0 GiB, swap\n",
+		"Incomplete Sections:\n  gpu (gpu): collector timed out\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output lacks %q:\n%s", want, got)
+		}
+	}
+	// Sections that were not asked for are left out
+	if strings.Contains(got, "Total Memory") {
+		t.Errorf("output has the memory section:\n%s", got)
+	}
+}
+
+func TestBuiltinEmpty(t *testing.T) {
+	// Every section of both layouts renders from a report with nothing collected
+	sections := []string{
+		helper.SectionHost, helper.SectionCPU, helper.SectionGPU, helper.SectionMotherboard,
+		helper.SectionMemory, helper.SectionStorage, helper.SectionNetwork, helper.SectionRouting,
+		helper.SectionBattery, helper.SectionPeripherals, helper.SectionSoftware,
+		helper.SectionPerformance, helper.SectionPackages, helper.SectionOther,
+	}
+	for _, info := range []any{helper.SysInfo{}, windows.SysInfo{}} {
+		text, err := Builtin(info, sections)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := Render(&strings.Builder{}, text, info, Options{}); err != nil {
+			t.Errorf("%T: %v", info, err)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package linux
+
+import (
+	"context"
+	"crypto/rand"
+	"defetch/helper"
+	"fmt"
+	mathrand "math/rand/v2"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	// Block sizes of the sequential and random tests, both multiples of the
+	// logical sector size that O_DIRECT requires
+	benchSequentialBlock = 1 << 20
+	benchRandomBlock     = 4 << 10
+
+	// benchMinFree is the share of the file system that must stay free with
+	// the test file written
+	benchMinFree = 0.1
+)
+
+// BenchmarkOptions holds the parameters of BenchmarkDisk
+type BenchmarkOptions struct {
+	Size     helper.Bytes  // Size of the test file, rounded down to whole MiB
+	Duration time.Duration // Time limit of each of the four tests
+}
+
+// BenchmarkMount runs BenchmarkDisk in dir, which must be absolute and free of
+// symlinks, and stores the results in the entry of storages for the disk the
+// directory is on
+func BenchmarkMount(ctx context.Context, storages []helper.StorageInfo, dir string, opts BenchmarkOptions) error {
+	disk, mountPoint, err := findMount(storages, dir)
+	if err != nil {
+		return err
+	}
+	bench, err := BenchmarkDisk(ctx, dir, opts)
+	if err != nil {
+		return err
+	}
+	bench.MountPoint = mountPoint
+	storages[disk].Benchmark = bench
+	return nil
+}
+
+// Helper function to find the disk and mount point that dir is on. Device
+// numbers are compared so that a tmpfs on /tmp is not taken for the disk
+// mounted on /.
+func findMount(storages []helper.StorageInfo, dir string) (int, string, error) {
+	var target syscall.Stat_t
+	if err := syscall.Stat(dir, &target); err != nil {
+		return -1, "", &os.PathError{Op: "stat", Path: dir, Err: err}
+	}
+
+	disk, mountPoint := -1, ""
+	for i, storage := range storages {
+		for _, mount := range storage.AllMounts() {
+			below := mount.MountPoint == "/" || dir == mount.MountPoint || strings.HasPrefix(dir, mount.MountPoint+"/")
+			if !below || len(mount.MountPoint) <= len(mountPoint) {
+				continue
+			}
+			var stat syscall.Stat_t
+			if err := syscall.Stat(mount.MountPoint, &stat); err == nil && stat.Dev == target.Dev {
+				disk, mountPoint = i, mount.MountPoint
+			}
+		}
+	}
+	if disk < 0 {
+		return -1, "", fmt.Errorf("%s is not on a disk", dir)
+	}
+	return disk, mountPoint, nil
+}
+
+// BenchmarkDisk measures sequential and random reads and writes on the file
+// system holding dir. The test file is opened with O_DIRECT so that the page
+// cache stays out of the figures, and is removed again on return, also when
+// ctx is cancelled.
+func BenchmarkDisk(ctx context.Context, dir string, opts BenchmarkOptions) (*helper.DiskBenchmark, error) {
+	opts.Size -= opts.Size % benchSequentialBlock
+	if opts.Size == 0 {
+		return nil, fmt.Errorf("size must be at least %v", helper.Bytes(benchSequentialBlock))
+	}
+	if opts.Duration <= 0 {
+		return nil, fmt.Errorf("duration must be positive")
+	}
+
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(dir, &stat); err != nil {
+		return nil, &os.PathError{Op: "statfs", Path: dir, Err: err}
+	}
+	size, _, available := statfsSizes(stat)
+	if err := checkFreeSpace(size, available, opts.Size); err != nil {
+		return nil, fmt.Errorf("%s: %w", dir, err)
+	}
+
+	file, err := os.CreateTemp(dir, ".defetch-bench-*")
+	if err != nil {
+		return nil, err
+	}
+	name := file.Name()
+	file.Close()
+	defer os.Remove(name)
+
+	file, err = os.OpenFile(name, os.O_RDWR|syscall.O_DIRECT, 0)
+	if err != nil {
+		return nil, fmt.Errorf("%s: file system does not support O_DIRECT: %w", dir, err)
+	}
+	defer file.Close()
+
+	// O_DIRECT needs buffers aligned to the sector size, which pages are.
+	// Random data keeps compressing and deduplicating disks from skipping work.
+	buf, err := syscall.Mmap(-1, 0, benchSequentialBlock, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_ANON|syscall.MAP_PRIVATE)
+	if err != nil {
+		return nil, fmt.Errorf("mmap: %w", err)
+	}
+	defer syscall.Munmap(buf)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+
+	bench := &helper.DiskBenchmark{Path: dir, FileSize: opts.Size, Duration: opts.Duration}
+	blocks := int64(opts.Size / benchSequentialBlock)
+
+	// Later tests only touch the part of the file the first one got to write
+	bench.SequentialWrite, err = benchmark(ctx, opts.Duration, file.Sync, func(n uint64) (int, error) {
+		return file.WriteAt(buf, int64(n)%blocks*benchSequentialBlock)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("sequential write: %w", err)
+	}
+	extent := min(int64(bench.SequentialWrite.Requests), blocks) * benchSequentialBlock
+	if extent == 0 {
+		return nil, fmt.Errorf("sequential write: no block written within %v", opts.Duration)
+	}
+
+	bench.SequentialRead, err = benchmark(ctx, opts.Duration, nil, func(n uint64) (int, error) {
+		return file.ReadAt(buf, int64(n)*benchSequentialBlock%extent)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("sequential read: %w", err)
+	}
+
+	bench.RandomWrite, err = benchmark(ctx, opts.Duration, file.Sync, func(uint64) (int, error) {
+		return file.WriteAt(buf[:benchRandomBlock], mathrand.Int64N(extent/benchRandomBlock)*benchRandomBlock)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("random write: %w", err)
+	}
+
+	bench.RandomRead, err = benchmark(ctx, opts.Duration, nil, func(uint64) (int, error) {
+		return file.ReadAt(buf[:benchRandomBlock], mathrand.Int64N(extent/benchRandomBlock)*benchRandomBlock)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("random read: %w", err)
+	}
+
+	return bench, nil
+}
+
+// Helper function to repeat op, which is given the number of the request,
+// until the duration is over. finish, if set, runs before the clock stops so
+// that writes count once they reach the disk.
+func benchmark(ctx context.Context, duration time.Duration, finish func() error, op func(n uint64) (int, error)) (helper.BenchmarkResult, error) {
+	var requests, transferred uint64
+	start := time.Now()
+	for time.Since(start) < duration {
+		if err := ctx.Err(); err != nil {
+			return helper.BenchmarkResult{}, err
+		}
+		n, err := op(requests)
+		if err != nil {
+			return helper.BenchmarkResult{}, err
+		}
+		requests++
+		transferred += uint64(n)
+	}
+	if finish != nil {
+		if err := finish(); err != nil {
+			return helper.BenchmarkResult{}, err
+		}
+	}
+	return benchmarkResult(requests, helper.Bytes(transferred), time.Since(start)), nil
+}
+
+// Helper function to turn the requests of a test and the bytes they moved into
+// rates. Requests are issued one at a time, so each took the average time.
+func benchmarkResult(requests uint64, transferred helper.Bytes, elapsed time.Duration) helper.BenchmarkResult {
+	result := helper.BenchmarkResult{Requests: requests}
+	if seconds := elapsed.Seconds(); seconds > 0 {
+		result.Speed = helper.Bytes(float64(transferred) / seconds)
+		result.IOPS = float64(requests) / seconds
+	}
+	if requests > 0 {
+		result.Latency = elapsed / time.Duration(requests)
+	}
+	return result
+}
+
+// Helper function to refuse a test file that would leave the file system
+// nearly full
+func checkFreeSpace(size, available, need helper.Bytes) error {
+	if available < need || float64(available-need) < float64(size)*benchMinFree {
+		return fmt.Errorf("only %v of %v available, refusing to write %v and leave less than %.0f%% free",
+			available, size, need, benchMinFree*100)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package linux
+
+import (
+	"context"
+	"defetch/helper"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBenchmarkResult(t *testing.T) {
+	// 500 requests of 4 KiB in two seconds
+	got := benchmarkResult(500, 500*4096, 2*time.Second)
+	want := helper.BenchmarkResult{Speed: 1024000, IOPS: 250, Latency: 4 * time.Millisecond, Requests: 500}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	if got := benchmarkResult(0, 0, 0); got != (helper.BenchmarkResult{}) {
+		t.Errorf("got %+v without requests", got)
+	}
+}
+
+func TestCheckFreeSpace(t *testing.T) {
+	const gib = helper.Bytes(1 << 30)
+	if err := checkFreeSpace(100*gib, 50*gib, gib); err != nil {
+		t.Error(err)
+	}
+	// Less than a tenth would be left, or the file would not fit at all
+	if err := checkFreeSpace(100*gib, 10*gib, gib); err == nil {
+		t.Error("no error for a nearly full file system")
+	}
+	if err := checkFreeSpace(100*gib, gib/2, gib); err == nil {
+		t.Error("no error for a file larger than the space available")
+	}
+}
+
+func TestBenchmarkDisk(t *testing.T) {
+	dir := t.TempDir()
+	bench, err := BenchmarkDisk(context.Background(), dir, BenchmarkOptions{Size: 2 << 20, Duration: 20 * time.Millisecond})
+	if err != nil && (strings.Contains(err.Error(), "O_DIRECT") || strings.Contains(err.Error(), "refusing")) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bench.FileSize != 2<<20 || bench.SequentialWrite.Requests == 0 || bench.RandomRead.Requests == 0 {
+		t.Errorf("got %+v", bench)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("test file %s left behind", entries[0].Name())
+	}
+
+	// Sizes below the block of the sequential tests are refused
+	if _, err := BenchmarkDisk(context.Background(), dir, BenchmarkOptions{Size: 4096, Duration: time.Millisecond}); err == nil {
+		t.Error("no error for a file smaller than 1 MiB")
+	}
+}
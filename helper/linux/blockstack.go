@@ -0,0 +1,261 @@
+package linux
+
+import (
+	"defetch/helper"
+	"errors"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// blockStack is the value of the block-stack collector: the devices stacked on
+// each disk and partition by kernel name, which GetLinuxInfo adds to the
+// storage section
+type blockStack map[string][]helper.BlockDeviceInfo
+
+var (
+	// mdMemberPattern matches a member in /proc/mdstat, e.g. sdb2[1](F)
+	mdMemberPattern = regexp.MustCompile(`^(.+)\[(\d+)\](\([A-Z]\))?$`)
+	// mdDevicesPattern matches the devices of an array and those in sync, e.g. [2/1]
+	mdDevicesPattern = regexp.MustCompile(`\[(\d+)/(\d+)\]`)
+	// mdSyncPattern matches the progress of a sync, e.g. recovery = 12.6%
+	mdSyncPattern = regexp.MustCompile(`(resync|recovery|check|repair|reshape)\s*=\s*([\d.]+)%`)
+)
+
+// stackReader holds what describing the devices of a block stack needs
+type stackReader struct {
+	fsys   fs.FS
+	arrays map[string]*helper.RAIDInfo
+	mounts mountTable
+	swaps  map[string]bool
+}
+
+// Helper function to get the md arrays, device-mapper targets and other
+// devices stacked on each disk and partition, following the holders links of
+// sysfs up to the devices file systems are mounted from
+func getBlockStack(fsys fs.FS) (blockStack, error) {
+	entries, err := fs.ReadDir(fsys, blockDir)
+	if err != nil {
+		return nil, err
+	}
+	arrays, mdErr := readMDStat(fsys)
+	mounts, mountsErr := readMounts(fsys)
+	r := stackReader{fsys: fsys, arrays: arrays, mounts: mounts, swaps: readSwaps(fsys)}
+
+	stack := make(blockStack)
+	for _, entry := range entries {
+		dir := path.Join(blockDir, entry.Name())
+		// Stacked devices are reached from the disks below them
+		if isStacked(fsys, dir) {
+			continue
+		}
+		if holders := r.holders(dir); len(holders) > 0 {
+			stack[entry.Name()] = holders
+		}
+
+		partitions, _ := fs.ReadDir(fsys, dir)
+		for _, partition := range partitions {
+			partDir := path.Join(dir, partition.Name())
+			if readSysfs(fsys, path.Join(partDir, "partition")) == "" {
+				continue
+			}
+			if holders := r.holders(partDir); len(holders) > 0 {
+				stack[partition.Name()] = holders
+			}
+		}
+	}
+	return stack, errors.Join(mdErr, mountsErr)
+}
+
+// Helper function to add the devices stacked on each disk and partition to the
+// storage section. The storage collector reports stacked devices as disks,
+// which are dropped once they show up below the disks they are built on.
+func addBlockStack(sysInfo *helper.SysInfo, stack blockStack) {
+	stacked := make(map[string]bool)
+	var mark func(devices []helper.BlockDeviceInfo)
+	mark = func(devices []helper.BlockDeviceInfo) {
+		for _, device := range devices {
+			stacked[device.Device] = true
+			mark(device.Holders)
+		}
+	}
+	for _, holders := range stack {
+		mark(holders)
+	}
+
+	storages := sysInfo.Storage[:0]
+	for _, storage := range sysInfo.Storage {
+		if stacked[storage.Device] {
+			continue
+		}
+		storage.Holders = stack[storage.Device]
+		for j := range storage.Partitions {
+			storage.Partitions[j].Holders = stack[storage.Partitions[j].Device]
+		}
+		storages = append(storages, storage)
+	}
+	sysInfo.Storage = storages
+}
+
+// Helper function to tell whether a block device is built on others, like md
+// arrays and device-mapper targets
+func isStacked(fsys fs.FS, dir string) bool {
+	slaves, _ := fs.ReadDir(fsys, path.Join(dir, "slaves"))
+	return len(slaves) > 0
+}
+
+// Helper function to get the devices holding the block device in dir
+func (r stackReader) holders(dir string) []helper.BlockDeviceInfo {
+	entries, _ := fs.ReadDir(r.fsys, path.Join(dir, "holders"))
+	var holders []helper.BlockDeviceInfo
+	for _, entry := range entries {
+		holders = append(holders, r.device(entry.Name()))
+	}
+	return holders
+}
+
+// Helper function to describe a stacked device and those stacked on it
+func (r stackReader) device(name string) helper.BlockDeviceInfo {
+	dir := path.Join(blockDir, name)
+	device := helper.BlockDeviceInfo{
+		Device: name,
+		Name:   readSysfs(r.fsys, path.Join(dir, "dm", "name")),
+		Type:   "dm",
+		Size:   parseBytes(readSysfs(r.fsys, path.Join(dir, "size"))) * sectorSize,
+	}
+
+	// The UUID of a target starts with the subsystem that set it up, e.g.
+	// LVM-<VG UUID><LV UUID> or CRYPT-LUKS2-<UUID>-<name>
+	uuid := readSysfs(r.fsys, path.Join(dir, "dm", "uuid"))
+	switch {
+	case r.arrays[name] != nil:
+		device.Type = "raid"
+		device.RAID = r.arrays[name]
+	case strings.HasPrefix(uuid, "LVM-"):
+		device.Type = "lvm"
+		device.VolumeGroup, device.LogicalVolume = splitLVMName(device.Name)
+	case strings.HasPrefix(uuid, "CRYPT-"):
+		device.Type = "crypt"
+		device.Encryption = strings.SplitN(uuid, "-", 3)[1]
+	}
+
+	// Device-mapper targets are mounted from /dev/mapper, which helps when
+	// btrfs reports another device number
+	source := name
+	if device.Name != "" {
+		source = "mapper/" + device.Name
+	}
+	device.Mounts = r.mounts.of(r.fsys, source, readSysfs(r.fsys, path.Join(dir, "dev")))
+	if len(device.Mounts) > 0 {
+		device.Filesystem = device.Mounts[0].FileSystem
+	} else if r.swaps[name] {
+		device.Filesystem = "swap"
+	}
+
+	device.Holders = r.holders(dir)
+	return device
+}
+
+// Helper function to split the device-mapper name of a logical volume into
+// volume group and logical volume. Dashes within either are doubled.
+func splitLVMName(name string) (string, string) {
+	for i := 0; i < len(name); i++ {
+		if name[i] != '-' {
+			continue
+		}
+		if i+1 < len(name) && name[i+1] == '-' {
+			i++
+			continue
+		}
+		return strings.ReplaceAll(name[:i], "--", "-"), strings.ReplaceAll(name[i+1:], "--", "-")
+	}
+	return strings.ReplaceAll(name, "--", "-"), ""
+}
+
+// Helper function to read the md arrays from /proc/mdstat. Systems without
+// the md driver have no such file and no arrays.
+func readMDStat(fsys fs.FS) (map[string]*helper.RAIDInfo, error) {
+	content, err := fs.ReadFile(fsys, "proc/mdstat")
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	arrays := make(map[string]*helper.RAIDInfo)
+	var array *helper.RAIDInfo
+	for _, line := range strings.Split(string(content), "\n") {
+		// md0 : active raid1 sdb2[2] sda2[0]
+		if name, rest, found := strings.Cut(line, " : "); found && strings.HasPrefix(name, "md") {
+			array = parseMDArray(strings.Fields(rest))
+			arrays[name] = array
+			continue
+		}
+		if array == nil {
+			continue
+		}
+
+		// 975568896 blocks super 1.2 [2/1] [U_]
+		if match := mdDevicesPattern.FindStringSubmatch(line); match != nil {
+			array.Devices, _ = strconv.Atoi(match[1])
+			array.ActiveDevices, _ = strconv.Atoi(match[2])
+			array.Degraded = array.ActiveDevices < array.Devices
+		}
+		// [==>..................]  recovery = 12.6% (122947584/975568896) finish=80.3min speed=176896K/sec
+		if match := mdSyncPattern.FindStringSubmatch(line); match != nil {
+			array.SyncAction = match[1]
+			array.SyncProgress, _ = strconv.ParseFloat(match[2], 64)
+		}
+		if strings.TrimSpace(line) == "" {
+			array = nil
+		}
+	}
+	return arrays, nil
+}
+
+// Helper function to parse the state, level and members that follow the name
+// of an array in /proc/mdstat. Inactive arrays have no level.
+func parseMDArray(fields []string) *helper.RAIDInfo {
+	array := &helper.RAIDInfo{}
+	if len(fields) > 0 {
+		array.State, fields = fields[0], fields[1:]
+	}
+	if len(fields) > 0 && strings.HasPrefix(fields[0], "(") {
+		array.State, fields = array.State+" "+fields[0], fields[1:]
+	}
+	if len(fields) > 0 && !mdMemberPattern.MatchString(fields[0]) {
+		array.Level, fields = fields[0], fields[1:]
+	}
+
+	type member struct {
+		name string
+		role int
+	}
+	var members []member
+	for _, field := range fields {
+		match := mdMemberPattern.FindStringSubmatch(field)
+		if match == nil {
+			continue
+		}
+		switch match[3] {
+		case "(F)":
+			array.Failed = append(array.Failed, match[1])
+		case "(S)":
+			array.Spares = append(array.Spares, match[1])
+		default:
+			role, _ := strconv.Atoi(match[2])
+			members = append(members, member{match[1], role})
+		}
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].role < members[j].role
+	})
+	for _, m := range members {
+		array.Members = append(array.Members, m.name)
+	}
+	return array
+}
@@ -0,0 +1,146 @@
+package linux
+
+import (
+	"context"
+	"defetch/helper"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBlockStack(t *testing.T) {
+	stack, err := getBlockStack(fixture("server"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Both disks hold /boot on md1 and the rest on md0, with LVM inside LUKS
+	boot := helper.BlockDeviceInfo{
+		Device: "md1", Type: "raid", Size: 4190208 * 512, Filesystem: "ext4",
+		RAID:   &helper.RAIDInfo{Level: "raid1", State: "active", Devices: 2, ActiveDevices: 2, Members: []string{"sda1", "sdb1"}},
+		Mounts: []helper.MountInfo{{MountPoint: "/boot", FileSystem: "ext4", Source: "/dev/md1", Root: "/", Options: []string{"rw", "relatime"}}},
+	}
+	root := helper.BlockDeviceInfo{
+		Device: "dm-1", Name: "vg0-root", Type: "lvm", Size: 50 << 30, VolumeGroup: "vg0", LogicalVolume: "root", Filesystem: "ext4",
+		Mounts: []helper.MountInfo{{MountPoint: "/", FileSystem: "ext4", Source: "/dev/mapper/vg0-root", Root: "/", Options: []string{"rw", "relatime", "errors=remount-ro"}}},
+	}
+	swap := helper.BlockDeviceInfo{Device: "dm-2", Name: "vg0-swap", Type: "lvm", Size: 16 << 30, VolumeGroup: "vg0", LogicalVolume: "swap", Filesystem: "swap"}
+	data := helper.BlockDeviceInfo{
+		Device: "dm-3", Name: "vg0-srv--data", Type: "lvm", Size: 800 << 30, VolumeGroup: "vg0", LogicalVolume: "srv-data", Filesystem: "xfs",
+		Mounts: []helper.MountInfo{{MountPoint: "/srv/data", FileSystem: "xfs", Source: "/dev/mapper/vg0-srv--data", Root: "/", Options: []string{
+			"rw", "relatime", "attr2", "inode64", "logbufs=8", "logbsize=32k", "noquota",
+		}}},
+	}
+	array := helper.BlockDeviceInfo{
+		Device: "md0", Type: "raid", Size: 1951137792 * 512,
+		RAID: &helper.RAIDInfo{
+			Level: "raid1", State: "active", Devices: 2, ActiveDevices: 1, Degraded: true,
+			Members: []string{"sda2", "sdb2"}, SyncAction: "recovery", SyncProgress: 12.6,
+		},
+		Holders: []helper.BlockDeviceInfo{{
+			Device: "dm-0", Name: "cryptroot", Type: "crypt", Size: (1951137792 - 32768) * 512, Encryption: "LUKS2",
+			Holders: []helper.BlockDeviceInfo{root, swap, data},
+		}},
+	}
+	want := blockStack{
+		"sda1": {boot}, "sda2": {array},
+		"sdb1": {boot}, "sdb2": {array},
+	}
+	if !reflect.DeepEqual(stack, want) {
+		t.Errorf("got %+v, want %+v", stack, want)
+	}
+
+	// Disks without stacked devices have no stack, and no mdstat is no error
+	if stack, err := getBlockStack(fixture("laptop")); err != nil || len(stack) != 0 {
+		t.Errorf("got %+v, %v for the laptop", stack, err)
+	}
+}
+
+func TestStorageWithBlockStack(t *testing.T) {
+	collectors, err := helper.SelectCollectors([]string{helper.SectionStorage})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sysInfo := GetLinuxInfo(context.Background(), collectors, helper.RunOptions{
+		FS:       fixture("server"),
+		Settings: map[string]helper.Settings{"storage": {"interval": "1ms"}},
+	})
+
+	// Arrays and device-mapper targets are not disks of their own
+	if len(sysInfo.Storage) != 2 || sysInfo.Storage[0].Device != "sda" || sysInfo.Storage[1].Device != "sdb" {
+		t.Fatalf("got %+v", sysInfo.Storage)
+	}
+	if holders := sysInfo.Storage[1].Partitions[1].Holders; len(holders) != 1 || holders[0].Device != "md0" {
+		t.Errorf("got holders %+v for sdb2", holders)
+	}
+
+	var mountPoints []string
+	for _, mount := range sysInfo.Storage[0].AllMounts() {
+		mountPoints = append(mountPoints, mount.MountPoint)
+	}
+	if want := []string{"/boot", "/", "/srv/data"}; !reflect.DeepEqual(mountPoints, want) {
+		t.Errorf("got mounts %v, want %v", mountPoints, want)
+	}
+	if err, ok := sysInfo.Errors[helper.SectionStorage]; ok {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestStorageWithoutBlockStack(t *testing.T) {
+	// "storage" also names the section, which would select block-stack too
+	var collectors []helper.Collector
+	for _, c := range helper.Collectors() {
+		if c.Name() == "storage" {
+			collectors = append(collectors, c)
+		}
+	}
+	sysInfo := GetLinuxInfo(context.Background(), collectors, helper.RunOptions{
+		FS:       fixture("server"),
+		Settings: map[string]helper.Settings{"storage": {"interval": "1ms"}},
+	})
+
+	// Without the stack arrays and device-mapper targets are listed as disks
+	var devices []string
+	for _, storage := range sysInfo.Storage {
+		devices = append(devices, storage.Device)
+	}
+	want := []string{"dm-0", "dm-1", "dm-2", "dm-3", "md0", "md1", "sda", "sdb"}
+	if !reflect.DeepEqual(devices, want) {
+		t.Errorf("got devices %v, want %v", devices, want)
+	}
+}
+
+func TestParseMDArray(t *testing.T) {
+	tests := map[string]helper.RAIDInfo{
+		"active raid5 sdd1[4](S) sdc1[2] sdb1[1](F) sda1[0]": {
+			Level: "raid5", State: "active", Members: []string{"sda1", "sdc1"}, Failed: []string{"sdb1"}, Spares: []string{"sdd1"},
+		},
+		"active (auto-read-only) raid1 nvme1n1p2[1] nvme0n1p2[0]": {
+			Level: "raid1", State: "active (auto-read-only)", Members: []string{"nvme0n1p2", "nvme1n1p2"},
+		},
+		// Inactive arrays have lost their level
+		"inactive sdb[1](S)": {State: "inactive", Spares: []string{"sdb"}},
+	}
+	for line, want := range tests {
+		got := parseMDArray(strings.Fields(line))
+		if !reflect.DeepEqual(*got, want) {
+			t.Errorf("%s: got %+v, want %+v", line, *got, want)
+		}
+	}
+}
+
+func TestSplitLVMName(t *testing.T) {
+	tests := map[string][2]string{
+		"vg0-root":          {"vg0", "root"},
+		"vg0-srv--data":     {"vg0", "srv-data"},
+		"my--vg-lv--a--b":   {"my-vg", "lv-a-b"},
+		"vg0-thin_tmeta":    {"vg0", "thin_tmeta"},
+		"lonely":            {"lonely", ""},
+		"ubuntu--vg-ubuntu": {"ubuntu-vg", "ubuntu"},
+	}
+	for name, want := range tests {
+		if vg, lv := splitLVMName(name); vg != want[0] || lv != want[1] {
+			t.Errorf("%s: got %q/%q, want %q/%q", name, vg, lv, want[0], want[1])
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package linux
+
+import (
+	"context"
+	"defetch/helper"
+	"io/fs"
+)
+
+func init() {
+	register("host", helper.SectionHost, func(ctx context.Context, fsys fs.FS) (any, error) { return getHostInfo(ctx, fsys) })
+	register("cpu", helper.SectionCPU, func(ctx context.Context, fsys fs.FS) (any, error) { return getCPUInfo(fsys) })
+	register("gpu", helper.SectionGPU, func(ctx context.Context, fsys fs.FS) (any, error) { return getGPUInfo(fsys) })
+	register("motherboard", helper.SectionMotherboard, func(ctx context.Context, fsys fs.FS) (any, error) { return getMotherboardInfo(ctx, fsys), nil })
+	register("memory", helper.SectionMemory, func(ctx context.Context, fsys fs.FS) (any, error) { return getMemoryInfo(ctx, fsys) })
+	register("storage", helper.SectionStorage, func(ctx context.Context, fsys fs.FS) (any, error) { return getStorageInfo(ctx, fsys) })
+	register("storage-health", helper.SectionStorage, func(ctx context.Context, fsys fs.FS) (any, error) { return getDiskHealth(ctx, fsys) })
+	register("block-stack", helper.SectionStorage, func(ctx context.Context, fsys fs.FS) (any, error) { return getBlockStack(fsys) })
+	register("network", helper.SectionNetwork, func(ctx context.Context, fsys fs.FS) (any, error) { return getNetworkInfo(fsys) })
+	register("routing", helper.SectionRouting, func(ctx context.Context, fsys fs.FS) (any, error) { return getRoutingInfo(fsys) })
+	register("battery", helper.SectionBattery, func(ctx context.Context, fsys fs.FS) (any, error) { return getPowerInfo(fsys) })
+	register("peripherals", helper.SectionPeripherals, func(ctx context.Context, fsys fs.FS) (any, error) { return getPeripheralsInfo(ctx), nil })
+	register("usb", helper.SectionPeripherals, func(ctx context.Context, fsys fs.FS) (any, error) { return getUSBDevices(fsys) })
+	register("software", helper.SectionSoftware, func(ctx context.Context, fsys fs.FS) (any, error) { return getSoftwareInfo(ctx, fsys) })
+	register("performance", helper.SectionPerformance, func(ctx context.Context, fsys fs.FS) (any, error) { return getPerformanceInfo(ctx, fsys) })
+	register("packages", helper.SectionPackages, func(ctx context.Context, fsys fs.FS) (any, error) { return getPackageManagementInfo(ctx, fsys) })
+	register("other", helper.SectionOther, func(ctx context.Context, fsys fs.FS) (any, error) { return getOtherInfo(ctx), nil })
+}
+
+// Helper function to register one of the built-in collectors, which read
+// files from the file system the collectors are run with
+func register(name, section string, fn func(ctx context.Context, fsys fs.FS) (any, error)) {
+	helper.Register(helper.NewCollector(name, section, func(ctx context.Context) (any, error) {
+		return fn(ctx, helper.CollectorFS(ctx))
+	}))
+}
+
+// Helper function to store a collected value in its section of sysInfo
+func setSection(sysInfo *helper.SysInfo, collector helper.Collector, value any) {
+	var ok bool
+	switch collector.Section() {
+	case helper.SectionHost:
+		ok = assign(&sysInfo.HostInfo, value)
+	case helper.SectionCPU:
+		ok = assign(&sysInfo.CPU, value)
+	case helper.SectionGPU:
+		ok = assign(&sysInfo.GPU, value)
+	case helper.SectionMotherboard:
+		ok = assign(&sysInfo.Motherboard, value)
+	case helper.SectionMemory:
+		ok = assign(&sysInfo.Memory, value)
+	case helper.SectionStorage:
+		ok = assign(&sysInfo.Storage, value)
+	case helper.SectionNetwork:
+		ok = assign(&sysInfo.Network, value)
+	case helper.SectionRouting:
+		ok = assign(&sysInfo.Routing, value)
+	case helper.SectionBattery:
+		ok = assign(&sysInfo.Power, value)
+	case helper.SectionPeripherals:
+		ok = assign(&sysInfo.Peripherals, value)
+	case helper.SectionSoftware:
+		ok = assign(&sysInfo.Software, value)
+	case helper.SectionPerformance:
+		ok = assign(&sysInfo.Performance, value)
+	case helper.SectionPackages:
+		ok = assign(&sysInfo.PackageManagement, value)
+	case helper.SectionOther:
+		ok = assign(&sysInfo.OtherInfo, value)
+	}
+
+	// Anything that doesn't fit a built-in section is kept as is
+	if !ok {
+		if sysInfo.Extra == nil {
+			sysInfo.Extra = make(map[string]any)
+		}
+		sysInfo.Extra[collector.Name()] = value
+	}
+}
+
+// Helper function to assign value to dst if it has the expected type
+func assign[T any](dst *T, value any) bool {
+	v, ok := value.(T)
+	if ok {
+		*dst = v
+	}
+	return ok
+}
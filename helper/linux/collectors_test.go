@@ -0,0 +1,124 @@
+package linux
+
+import (
+	"context"
+	"defetch/helper"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+// Every collector runs against the fixture root, and only commands, which
+// would describe the machine running the tests, are refused
+func TestCollectorsBelowRoot(t *testing.T) {
+	collectors, err := helper.SelectCollectors(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sysInfo := GetLinuxInfo(context.Background(), collectors, helper.RunOptions{
+		Workers:  4,
+		FS:       fixture("laptop"),
+		Settings: map[string]helper.Settings{"performance": {"interval": "1ms"}, "storage": {"interval": "1ms"}},
+	})
+
+	if sysInfo.Hostname != "thinkpad" {
+		t.Errorf("host: got hostname %q", sysInfo.Hostname)
+	}
+	if sysInfo.CPU.Threads != 4 {
+		t.Errorf("cpu: got %d threads", sysInfo.CPU.Threads)
+	}
+	if len(sysInfo.GPU) != 1 {
+		t.Errorf("gpu: got %d GPUs", len(sysInfo.GPU))
+	}
+	if sysInfo.Motherboard.Manufacturer != "LENOVO" {
+		t.Errorf("motherboard: got %+v", sysInfo.Motherboard)
+	}
+	if sysInfo.Memory.TotalSize != 16000000*1024 || len(sysInfo.Memory.Zram) != 1 {
+		t.Errorf("memory: got %+v", sysInfo.Memory)
+	}
+	if len(sysInfo.Power.Batteries) != 2 {
+		t.Errorf("battery: got %+v", sysInfo.Power)
+	}
+	if len(sysInfo.Peripherals.USBDevices) != 2 {
+		t.Errorf("peripherals: got %d USB devices", len(sysInfo.Peripherals.USBDevices))
+	}
+	if sysInfo.Software.OSDetails != "debian 12" {
+		t.Errorf("software: got OS %q", sysInfo.Software.OSDetails)
+	}
+	// The same /proc/stat is read twice, so no time has passed
+	if sysInfo.Performance.CPUTimes.Idle != 100 || sysInfo.Performance.MemoryUsage.Total != 16000000*1024 {
+		t.Errorf("performance: got %+v", sysInfo.Performance)
+	}
+	if len(sysInfo.Storage) != 3 {
+		t.Errorf("storage: got %d disks", len(sysInfo.Storage))
+	}
+	if len(sysInfo.Network) != 6 {
+		t.Errorf("network: got %d interfaces", len(sysInfo.Network))
+	}
+	if len(sysInfo.PackageManagement.RecentlyInstalledPackages) != 1 {
+		t.Errorf("packages: got %+v", sysInfo.PackageManagement.RecentlyInstalledPackages)
+	}
+
+	for _, section := range []string{helper.SectionSoftware, helper.SectionPackages} {
+		err, ok := sysInfo.Errors[section]
+		if !ok || !errors.Is(err, helper.ErrOtherRoot) {
+			t.Errorf("%s: got error %v, want commands refused", section, err)
+		}
+	}
+	for _, section := range []string{helper.SectionHost, helper.SectionCPU, helper.SectionGPU, helper.SectionMemory, helper.SectionStorage, helper.SectionNetwork, helper.SectionRouting, helper.SectionBattery} {
+		if err, ok := sysInfo.Errors[section]; ok {
+			t.Errorf("%s: unexpected error %v", section, err)
+		}
+	}
+}
+
+// Collectors built on commands are tested with the recordings in testdata/recordings
+func TestCollectorsFromRecordings(t *testing.T) {
+	collectors, err := helper.SelectCollectors([]string{
+		helper.SectionMemory, helper.SectionStorage, helper.SectionPackages, helper.SectionOther,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sysInfo := GetLinuxInfo(context.Background(), collectors, helper.RunOptions{
+		FS:       fixture("laptop"),
+		Runner:   helper.ReplayRunner(filepath.Join("testdata", "recordings", "laptop")),
+		Settings: map[string]helper.Settings{"storage": {"interval": "1ms"}},
+	})
+
+	// dmidecode was not installed when the memory slots were recorded
+	if len(sysInfo.Memory.Slots) != 0 {
+		t.Errorf("memory: got slots %+v", sysInfo.Memory.Slots)
+	}
+
+	if sysInfo.PackageManagement.PackageCount != 5 {
+		t.Errorf("packages: got %d installed", sysInfo.PackageManagement.PackageCount)
+	}
+	// apt list --upgradable was not recorded
+	if err := sysInfo.Errors[helper.SectionPackages]; !errors.Is(err, helper.ErrNotRecorded) {
+		t.Errorf("packages: got error %v, want ErrNotRecorded", err)
+	}
+
+	// smartctl reads the NVMe disk but not the USB stick behind its bridge
+	if len(sysInfo.Storage) != 3 {
+		t.Fatalf("storage: got %d disks", len(sysInfo.Storage))
+	}
+	nvme, stick := sysInfo.Storage[0].Health, sysInfo.Storage[1].Health
+	if nvme == nil || nvme.Source != "smartctl" || *nvme.PowerOnHours != 4210 || *nvme.PercentageUsed != 3 {
+		t.Errorf("storage: got health %+v for nvme0n1", nvme)
+	}
+	if stick != nil {
+		t.Errorf("storage: got health %+v for sda", stick)
+	}
+	if err, ok := sysInfo.Errors[helper.SectionStorage]; ok {
+		t.Errorf("storage: unexpected error %v", err)
+	}
+
+	other := sysInfo.OtherInfo
+	if other.PublicIP != "203.0.113.7" || other.Timezone != "Europe/Berlin" {
+		t.Errorf("other: got public IP %q and timezone %q", other.PublicIP, other.Timezone)
+	}
+	if other.Temperature.CPU == nil || *other.Temperature.CPU != 45 {
+		t.Errorf("other: got CPU temperature %v", other.Temperature.CPU)
+	}
+}
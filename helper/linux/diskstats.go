@@ -0,0 +1,117 @@
+package linux
+
+import (
+	"context"
+	"defetch/helper"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// diskCounters holds the first columns of a /proc/diskstats line after the
+// device name, which every kernel since 2.6 has: reads, reads merged, sectors
+// read, ms reading, writes, writes merged, sectors written, ms writing, I/Os in
+// flight, ms doing I/O and weighted ms doing I/O
+type diskCounters [11]uint64
+
+// Helper function to read the counters of each block device from /proc/diskstats
+func readDiskStats(fsys fs.FS) (map[string]diskCounters, error) {
+	content, err := fs.ReadFile(fsys, "proc/diskstats")
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make(map[string]diskCounters)
+	for _, line := range strings.Split(string(content), "\n") {
+		// Major Minor Name counters...
+		fields := strings.Fields(line)
+		if len(fields) < 3+len(diskCounters{}) {
+			continue
+		}
+
+		var counters diskCounters
+		for i := range counters {
+			value, err := strconv.ParseUint(fields[3+i], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("/proc/diskstats: %s: %w", fields[2], err)
+			}
+			counters[i] = value
+		}
+		stats[fields[2]] = counters
+	}
+	return stats, nil
+}
+
+// Helper function to sample /proc/diskstats twice. The "interval" setting of
+// the storage collector sets the time between the samples.
+func sampleDiskStats(ctx context.Context, fsys fs.FS) (map[string]helper.DiskIO, error) {
+	interval, err := helper.CollectorSettings(ctx).Duration("interval", defaultSampleInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	before, err := readDiskStats(fsys)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	after, err := readDiskStats(fsys)
+	if err != nil {
+		return nil, err
+	}
+	elapsed := time.Since(start)
+
+	disks := make(map[string]helper.DiskIO)
+	for name, counters := range after {
+		disks[name] = diskIOBetween(before[name], counters, elapsed)
+	}
+	return disks, nil
+}
+
+// Helper function to turn the counters of two samples taken elapsed apart into
+// rates, the way iostat does
+func diskIOBetween(before, after diskCounters, elapsed time.Duration) helper.DiskIO {
+	var delta diskCounters
+	for i := range after {
+		// Counters restart when a device is removed and added again
+		if after[i] > before[i] {
+			delta[i] = after[i] - before[i]
+		}
+	}
+
+	io := helper.DiskIO{
+		SampleInterval: elapsed,
+		ReadBytes:      helper.Bytes(after[2] * sectorSize),
+		WrittenBytes:   helper.Bytes(after[6] * sectorSize),
+		Reads:          after[0],
+		Writes:         after[4],
+		BusyTime:       time.Duration(after[9]) * time.Millisecond,
+	}
+	if seconds := elapsed.Seconds(); seconds > 0 {
+		io.ReadSpeed = helper.Bytes(float64(delta[2]*sectorSize) / seconds)
+		io.WriteSpeed = helper.Bytes(float64(delta[6]*sectorSize) / seconds)
+		io.ReadIOPS = float64(delta[0]) / seconds
+		io.WriteIOPS = float64(delta[4]) / seconds
+		// Time spent on I/O is counted in ms
+		io.QueueDepth = float64(delta[10]) / (seconds * 1000)
+		io.Utilization = min(float64(delta[9])/(seconds*1000)*100, 100)
+	}
+	if delta[0] > 0 {
+		io.ReadLatency = time.Duration(delta[3]) * time.Millisecond / time.Duration(delta[0])
+	}
+	if delta[4] > 0 {
+		io.WriteLatency = time.Duration(delta[7]) * time.Millisecond / time.Duration(delta[4])
+	}
+	return io
+}
@@ -0,0 +1,58 @@
+package linux
+
+import (
+	"defetch/helper"
+	"testing"
+	"time"
+)
+
+func TestReadDiskStats(t *testing.T) {
+	stats, err := readDiskStats(fixture("laptop"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 7 {
+		t.Errorf("got %d devices, want 7", len(stats))
+	}
+	want := diskCounters{245112, 80433, 13485210, 41230, 512877, 310221, 40123984, 402118, 0, 298120, 452304}
+	if got := stats["nvme0n1"]; got != want {
+		t.Errorf("nvme0n1: got %v, want %v", got, want)
+	}
+
+	if _, err := readDiskStats(fixture("convertible")); err == nil {
+		t.Error("no error without /proc/diskstats")
+	}
+}
+
+func TestDiskIOBetween(t *testing.T) {
+	// Two seconds with 200 reads of 8 KiB and 50 writes of 64 KiB
+	before := diskCounters{1000, 0, 20000, 500, 400, 0, 80000, 1200, 0, 3000, 4000}
+	after := diskCounters{1200, 0, 23200, 900, 450, 0, 86400, 1700, 2, 4000, 5800}
+
+	got := diskIOBetween(before, after, 2*time.Second)
+	want := helper.DiskIO{
+		ReadSpeed:      819200,
+		WriteSpeed:     1638400,
+		ReadIOPS:       100,
+		WriteIOPS:      25,
+		ReadLatency:    2 * time.Millisecond,
+		WriteLatency:   10 * time.Millisecond,
+		QueueDepth:     0.9,
+		Utilization:    50,
+		SampleInterval: 2 * time.Second,
+		ReadBytes:      23200 * 512,
+		WrittenBytes:   86400 * 512,
+		Reads:          1200,
+		Writes:         450,
+		BusyTime:       4 * time.Second,
+	}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	// Without activity in between only the totals are known
+	idle := diskIOBetween(after, after, time.Second)
+	if idle.ReadSpeed != 0 || idle.ReadLatency != 0 || idle.Utilization != 0 || idle.Reads != 1200 {
+		t.Errorf("got %+v for identical samples", idle)
+	}
+}
@@ -1,32 +1,94 @@
 package linux
 
 import (
+	"context"
 	"defetch/helper"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
-	"os/exec"
 	"os/user"
-	"path/filepath"
+	"path"
 	"runtime"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/shirou/gopsutil/host"
 	"golang.org/x/sys/unix"
 )
 
-func GetLinuxInfo() helper.SysInfo {
+// GetLinuxInfo runs the collectors and assembles their results
+func GetLinuxInfo(ctx context.Context, collectors []helper.Collector, opts helper.RunOptions) helper.SysInfo {
+	sysInfo := helper.SysInfo{
+		SchemaVersion: helper.SchemaVersion,
+		CollectedAt:   time.Now().UTC(),
+		Timings:       make(map[string]time.Duration),
+	}
+	var health diskHealth
+	var stack blockStack
+	var usb usbDevices
+	for _, result := range helper.RunCollectors(ctx, collectors, opts) {
+		sysInfo.Timings[result.Collector.Name()] = result.Duration
+		if result.Err != nil {
+			if sysInfo.Errors == nil {
+				sysInfo.Errors = make(map[string]*helper.SectionError)
+			}
+			helper.AddSectionError(sysInfo.Errors, result.Collector, result.Err)
+		}
+		// Failing collectors may still return partial information
+		switch value := result.Value.(type) {
+		case nil:
+		case diskHealth:
+			// The disks these and stacked devices belong to may come later
+			health = value
+		case blockStack:
+			// md arrays and device-mapper targets stay disks of their own
+			// unless the whole stack they belong to is known
+			if result.Err == nil {
+				stack = value
+			}
+		case usbDevices:
+			// The peripherals section may come later
+			usb = value
+		default:
+			setSection(&sysInfo, result.Collector, result.Value)
+		}
+	}
+	addDiskHealth(&sysInfo, health)
+	addBlockStack(&sysInfo, stack)
+	sysInfo.Peripherals.USBDevices = usb
+
+	return sysInfo
+}
+
+// Helper function to get host information
+func getHostInfo(ctx context.Context, fsys fs.FS) (helper.HostInfo, error) {
+	osRelease := readOSRelease(fsys)
+
+	// System calls and the environment describe the running system, so below
+	// another root only what its files tell is reported
+	if !helper.IsLive(fsys) {
+		return helper.HostInfo{
+			Hostname:  readSysfs(fsys, "etc/hostname"),
+			OSName:    osRelease["ID"],
+			OSVersion: osRelease["VERSION_ID"],
+			OSID:      osRelease["ID"],
+			OSIDLike:  strings.Fields(osRelease["ID_LIKE"]),
+		}, nil
+	}
+
 	// Hostname
-	hostname, _ := os.Hostname()
+	hostname, hostnameErr := os.Hostname()
 
 	// Current User
-	currentUser, _ := user.Current()
+	var username string
+	if currentUser, err := user.Current(); err == nil {
+		username = currentUser.Username
+	}
 
 	// Operating System
-	platform, family, version, _ := host.PlatformInformation()
-	osName := platform
-	osVersion := version
-	osCodename := family
+	platform, family, version, platformErr := host.PlatformInformation()
 
 	// Kernel Version
 	var uname unix.Utsname
@@ -36,74 +98,51 @@ func GetLinuxInfo() helper.SysInfo {
 	// Shell and Version
 	shell := os.Getenv("SHELL")
 	shellBinary := getShellBinary(shell)
-	shellVersion := getShellVersion(shellBinary)
-
-	// Architecture
-	architecture := runtime.GOARCH
+	shellVersion := getShellVersion(ctx, shellBinary)
 
 	// Uptime
 	uptime, _ := host.Uptime()
-	uptimeStr := formatUptime(uptime)
-
-	// CPU Information
-	cpuInfo := getCPUInfo()
-
-	// GPU Information
-	gpuInfo := getGPUInfo()
-
-	// Motherboard Information
-	motherboardInfo := getMotherboardInfo()
-
-	// Memory Information
-	memoryInfo := getMemoryInfo()
-
-	// Storage Information
-	storageInfo := getStorageInfo()
-
-	// Network Information
-	networkInfo := getNetworkInfo()
-
-	// Battery Information
-	// batteryInfo := getBatteryInfo()
-
-	// Peripherals Information
-	peripheralsInfo := getPeripheralsInfo()
-
-	// Software Information
-	softwareInfo := getSoftwareInfo()
-
-	// Performance Information
-	performanceInfo := getPerformanceInfo()
-
-	// Package Management Information
-	packageManagementInfo := getPackageManagementInfo()
-
-	// Other Information
-	otherInfo := getOtherInfo()
-
-	return helper.SysInfo{
-		Hostname:          hostname,
-		CurrentUser:       currentUser.Username,
-		OSName:            osName,
-		OSVersion:         osVersion,
-		OSCodename:        osCodename,
-		KernelVersion:     kernelVersion,
-		Shell:             shellBinary,
-		ShellVersion:      shellVersion,
-		Architecture:      architecture,
-		Uptime:            uptimeStr,
-		CPU:               cpuInfo,
-		GPU:               gpuInfo,
-		Motherboard:       motherboardInfo,
-		Memory:            memoryInfo,
-		Storage:           storageInfo,
-		Network:           networkInfo,
-		Peripherals:       peripheralsInfo,
-		Software:          softwareInfo,
-		Performance:       performanceInfo,
-		PackageManagement: packageManagementInfo,
-		OtherInfo:         otherInfo,
+
+	return helper.HostInfo{
+		Hostname:      hostname,
+		CurrentUser:   username,
+		OSName:        platform,
+		OSVersion:     version,
+		OSCodename:    family,
+		OSID:          osRelease["ID"],
+		OSIDLike:      strings.Fields(osRelease["ID_LIKE"]),
+		KernelVersion: kernelVersion,
+		Shell:         shellBinary,
+		ShellVersion:  shellVersion,
+		Architecture:  runtime.GOARCH,
+		Uptime:        formatUptime(uptime),
+	}, errors.Join(hostnameErr, platformErr)
+}
+
+// Helper function to read the key/value pairs of os-release
+func readOSRelease(fsys fs.FS) map[string]string {
+	release := make(map[string]string)
+	for _, name := range []string{"etc/os-release", "usr/lib/os-release"} {
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(content), "\n") {
+			key, value, found := strings.Cut(strings.TrimSpace(line), "=")
+			if !found || strings.HasPrefix(key, "#") {
+				continue
+			}
+			release[key] = strings.Trim(value, `"'`)
+		}
+		break
 	}
+	return release
+}
+
+// Helper function to run a command with the Runner of the collector and
+// return its standard output
+func runCommand(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return helper.CollectorRunner(ctx).Run(ctx, name, args...)
 }
 
 // Helper function to convert Unix Utsname to a string
@@ -148,25 +187,25 @@ func getShellBinary(shellPath string) string {
 }
 
 // Helper function to get the shell version
-func getShellVersion(shellBinary string) string {
+func getShellVersion(ctx context.Context, shellBinary string) string {
 	var output []byte
 	var err error
 
 	switch shellBinary {
 	case "bash":
-		output, err = exec.Command(shellBinary, "--version").Output()
+		output, err = runCommand(ctx, shellBinary, "--version")
 	case "zsh":
-		output, err = exec.Command(shellBinary, "--version").Output()
+		output, err = runCommand(ctx, shellBinary, "--version")
 	case "fish":
-		output, err = exec.Command(shellBinary, "--version").Output()
+		output, err = runCommand(ctx, shellBinary, "--version")
 	case "sh":
-		output, err = exec.Command(shellBinary, "--version").Output()
+		output, err = runCommand(ctx, shellBinary, "--version")
 	default:
-		return "Unknown shell or version not available"
+		return ""
 	}
 
 	if err != nil {
-		return "Version not available"
+		return ""
 	}
 
 	firstLine := strings.SplitN(strings.TrimSpace(string(output)), "\n", 2)[0]
@@ -174,19 +213,22 @@ func getShellVersion(shellBinary string) string {
 }
 
 // Helper function to get CPU information
-func getCPUInfo() helper.CPUInfo {
+func getCPUInfo(fsys fs.FS) (helper.CPUInfo, error) {
 	// Read from /proc/cpuinfo
-	output, err := os.ReadFile("/proc/cpuinfo")
+	output, err := fs.ReadFile(fsys, "proc/cpuinfo")
 	if err != nil {
-		panic("Cannot read /proc/cpuinfo")
+		return helper.CPUInfo{}, err
 	}
 
 	lines := strings.Split(string(output), "\n")
 	var modelName, flags string
-	var cores, cacheSize int
+	var cores, threads, cacheSize int
 	var frequency float64
 
 	for _, line := range lines {
+		if strings.HasPrefix(line, "processor") {
+			threads++
+		}
 		if strings.Contains(line, "model name") && modelName == "" {
 			modelName = strings.TrimSpace(strings.Split(line, ":")[1])
 		}
@@ -194,7 +236,8 @@ func getCPUInfo() helper.CPUInfo {
 			cores, _ = strconv.Atoi(strings.TrimSpace(strings.Split(line, ":")[1]))
 		}
 		if strings.Contains(line, "cache size") {
-			cacheSize, _ = strconv.Atoi(strings.TrimSpace(strings.Split(line, ":")[1]))
+			// The size is followed by its unit, e.g. "8192 KB"
+			cacheSize, _ = strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(strings.Split(line, ":")[1]), " KB"))
 		}
 		if strings.Contains(line, "cpu MHz") {
 			frequency, _ = strconv.ParseFloat(strings.TrimSpace(strings.Split(line, ":")[1]), 64)
@@ -206,14 +249,16 @@ func getCPUInfo() helper.CPUInfo {
 
 	// Check if model name is empty, fallback to /proc/device-tree/model for ARM systems
 	if modelName == "" {
-		deviceModel, err := os.ReadFile("/proc/device-tree/model")
+		deviceModel, err := fs.ReadFile(fsys, "proc/device-tree/model")
 		if err == nil {
-			modelName = strings.TrimSpace(string(deviceModel))
+			modelName = strings.Trim(string(deviceModel), "\x00\n ")
 		}
 	}
 
-	// Get number of logical CPUs
-	threads := runtime.NumCPU()
+	// Get number of logical CPUs, which every architecture lists as processor
+	if threads == 0 {
+		threads = runtime.NumCPU()
+	}
 
 	return helper.CPUInfo{
 		ModelName:    modelName,
@@ -223,45 +268,15 @@ func getCPUInfo() helper.CPUInfo {
 		Frequency:    frequency,
 		CacheSize:    int32(cacheSize),
 		Flags:        flags,
-	}
-}
-
-// Helper function to get GPU information
-func getGPUInfo() helper.GPUInfo {
-	var modelName, driverVersion, memorySize string
-
-	// Use lshw command to get GPU info
-	output, err := exec.Command("lshw", "-C", "display").Output()
-	if err != nil {
-		panic("Cannot execute lshw command")
-	}
-
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		if strings.Contains(line, "product:") {
-			modelName = strings.TrimSpace(strings.Split(line, ":")[1])
-		}
-		if strings.Contains(line, "version:") {
-			driverVersion = strings.TrimSpace(strings.Split(line, ":")[1])
-		}
-		if strings.Contains(line, "size:") && strings.Contains(line, "memory") {
-			memorySize = strings.TrimSpace(strings.Split(line, ":")[1])
-		}
-	}
-
-	return helper.GPUInfo{
-		ModelName:     modelName,
-		DriverVersion: driverVersion,
-		MemorySize:    memorySize,
-	}
+	}, nil
 }
 
 // Helper function to get Motherboard information
-func getMotherboardInfo() helper.MotherboardInfo {
-	manufacturer := readSysFileOrFallback("/sys/class/dmi/id/board_vendor", "dmidecode -s baseboard-manufacturer")
-	model := readSysFileOrFallback("/sys/class/dmi/id/board_name", "dmidecode -s baseboard-product-name")
-	biosVersion := readSysFileOrFallback("/sys/class/dmi/id/bios_version", "dmidecode -s bios-version")
-	serialNumber := readSysFileOrFallback("/sys/class/dmi/id/board_serial", "dmidecode -s baseboard-serial-number")
+func getMotherboardInfo(ctx context.Context, fsys fs.FS) helper.MotherboardInfo {
+	manufacturer := readSysFileOrFallback(ctx, fsys, "sys/class/dmi/id/board_vendor", "dmidecode -s baseboard-manufacturer")
+	model := readSysFileOrFallback(ctx, fsys, "sys/class/dmi/id/board_name", "dmidecode -s baseboard-product-name")
+	biosVersion := readSysFileOrFallback(ctx, fsys, "sys/class/dmi/id/bios_version", "dmidecode -s bios-version")
+	serialNumber := readSysFileOrFallback(ctx, fsys, "sys/class/dmi/id/board_serial", "dmidecode -s baseboard-serial-number")
 
 	return helper.MotherboardInfo{
 		Manufacturer: manufacturer,
@@ -271,42 +286,45 @@ func getMotherboardInfo() helper.MotherboardInfo {
 	}
 }
 
-// parseSize converts a memory size string (e.g., "4096 kB") into an integer value in kilobytes.
-func parseSize(sizeStr string) int64 {
+// parseSize converts a memory size string (e.g., "4096 kB") into bytes.
+func parseSize(sizeStr string) helper.Bytes {
 	sizeParts := strings.Fields(sizeStr)
-	size, err := strconv.ParseInt(sizeParts[0], 10, 64)
+	if len(sizeParts) == 0 {
+		return 0
+	}
+	size, err := strconv.ParseUint(sizeParts[0], 10, 64)
+	if err != nil {
+		return 0
+	}
+	if len(sizeParts) > 1 && sizeParts[1] == "kB" {
+		size *= 1024
+	}
+	return helper.Bytes(size)
+}
+
+// parseBytes converts a plain byte count as found in sysfs
+func parseBytes(value string) helper.Bytes {
+	size, err := strconv.ParseUint(value, 10, 64)
 	if err != nil {
 		return 0
 	}
-	return size
+	return helper.Bytes(size)
 }
 
 // Helper function to get Memory information
-func getMemoryInfo() helper.MemoryInfo {
-	var totalSize, usedSize, freeSize string
+func getMemoryInfo(ctx context.Context, fsys fs.FS) (helper.MemoryInfo, error) {
 	var slots []helper.MemorySlotInfo
 
 	// Use /proc/meminfo to get memory usage
-	meminfoOutput, err := os.ReadFile("/proc/meminfo")
+	meminfo, err := readMeminfo(fsys)
 	if err != nil {
-		panic("Cannot read /proc/meminfo")
-	}
-
-	lines := strings.Split(string(meminfoOutput), "\n")
-	for _, line := range lines {
-		if strings.HasPrefix(line, "MemTotal:") {
-			totalSize = strings.TrimSpace(strings.Fields(line)[1]) + " kB"
-		}
-		if strings.HasPrefix(line, "MemFree:") {
-			freeSize = strings.TrimSpace(strings.Fields(line)[1]) + " kB"
-		}
+		return helper.MemoryInfo{}, err
 	}
-
-	// Calculate used size
-	usedSize = fmt.Sprintf("%d kB", parseSize(totalSize)-parseSize(freeSize))
+	memoryInfo := meminfo.memoryInfo()
+	memoryInfo.Zram = getZramDevices(fsys)
 
 	// Use dmidecode as a fallback for memory slot information if sysfs is unavailable
-	dmidecodeOutput, err := exec.Command("dmidecode", "-t", "memory").Output()
+	dmidecodeOutput, err := runCommand(ctx, "dmidecode", "-t", "memory")
 	if err == nil {
 		dmidecodeLines := strings.Split(string(dmidecodeOutput), "\n")
 		var currentSlot helper.MemorySlotInfo
@@ -332,220 +350,37 @@ func getMemoryInfo() helper.MemoryInfo {
 		}
 	}
 
-	return helper.MemoryInfo{
-		TotalSize: totalSize,
-		UsedSize:  usedSize,
-		FreeSize:  freeSize,
-		Slots:     slots,
-	}
+	memoryInfo.Slots = slots
+	return memoryInfo, nil
 }
 
 // Helper function to read from sysfs or fallback to dmidecode command
-func readSysFileOrFallback(sysFilePath string, dmidecodeCmd string) string {
-	content, err := os.ReadFile(sysFilePath)
+func readSysFileOrFallback(ctx context.Context, fsys fs.FS, sysFilePath string, dmidecodeCmd string) string {
+	content, err := fs.ReadFile(fsys, sysFilePath)
 	if err == nil {
 		return strings.TrimSpace(string(content))
 	}
 
 	// Fallback to using dmidecode
-	output, err := exec.Command("sh", "-c", dmidecodeCmd).Output()
+	output, err := runCommand(ctx, "sh", "-c", dmidecodeCmd)
 	if err != nil {
-		return "Unknown"
+		return ""
 	}
 	return strings.TrimSpace(string(output))
 }
 
-// Helper function to get Storage information
-func getStorageInfo() []helper.StorageInfo {
-	var storages []helper.StorageInfo
-
-	// Use lsblk command to get block device information
-	output, err := exec.Command("lsblk", "-d", "-o", "NAME,MODEL,SIZE,ROTA,RM").Output()
-	if err != nil {
-		panic("Cannot execute lsblk command")
-	}
-
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines[1:] {
-		parts := strings.Fields(line)
-		if len(parts) < 3 {
-			continue
-		}
-
-		device := parts[0]
-		model := parts[1]
-		capacity := parts[2]
-
-		// Use df command to get used and available space, and file system type
-		dfOutput, err := exec.Command("df", "-hT", "/dev/"+device).Output()
-		if err != nil {
-			continue
-		}
-
-		dfLines := strings.Split(string(dfOutput), "\n")
-		if len(dfLines) < 2 {
-			continue
-		}
-
-		dfParts := strings.Fields(dfLines[1])
-		if len(dfParts) < 7 {
-			continue
-		}
-
-		filesystem := dfParts[1]
-		used := dfParts[2]
-		available := dfParts[3]
-		mountPoint := dfParts[6]
-
-		storages = append(storages, helper.StorageInfo{
-			Device:     device,
-			Model:      model,
-			Capacity:   capacity,
-			Used:       used,
-			Available:  available,
-			FileSystem: filesystem,
-			MountPoint: mountPoint,
-			ReadSpeed:  "Unknown", // Placeholder, as getting read speed dynamically is complex
-			WriteSpeed: "Unknown", // Placeholder, as getting write speed dynamically is complex
-		})
-	}
-
-	return storages
-}
-
-// Helper function to get Network information
-func getNetworkInfo() []helper.NetworkInfo {
-	var networks []helper.NetworkInfo
-
-	// Use ip command to get network interfaces
-	output, err := exec.Command("ip", "-o", "addr", "show").Output()
-	if err != nil {
-		panic("Cannot execute ip command")
-	}
-
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		if line == "" {
-			continue
-		}
-		fields := strings.Fields(line)
-		interfaceName := fields[1]
-		ipAddress := fields[3]
-
-		// Get MAC address
-		macOutput, err := exec.Command("cat", fmt.Sprintf("/sys/class/net/%s/address", interfaceName)).Output()
-		macAddress := "Unknown"
-		if err == nil {
-			macAddress = strings.TrimSpace(string(macOutput))
-		}
-
-		// Check if interface is active
-		active := strings.Contains(fields[len(fields)-1], "state UP")
-
-		// Get network speed (placeholder, as getting actual speed is complex)
-		speed := "Unknown"
-
-		// Get default gateway
-		gatewayOutput, err := exec.Command("ip", "route", "show", "default").Output()
-		defaultGateway := "Unknown"
-		if err == nil {
-			gatewayLines := strings.Split(string(gatewayOutput), "\n")
-			if len(gatewayLines) > 0 {
-				gatewayFields := strings.Fields(gatewayLines[0])
-				if len(gatewayFields) > 2 {
-					defaultGateway = gatewayFields[2]
-				}
-			}
-		}
-
-		networks = append(networks, helper.NetworkInfo{
-			InterfaceName:  interfaceName,
-			IPAddress:      ipAddress,
-			MACAddress:     macAddress,
-			Speed:          speed,
-			Active:         active,
-			DefaultGateway: defaultGateway,
-		})
-	}
-
-	return networks
-}
-
-// NOTE FOR ME: /sys/class/power_supply/BAT0/ NOT WORKING, TODO LATER
-// Helper function to get Battery information
-// func getBatteryInfo() helper.BatteryInfo {
-// 	// Placeholder values, actual implementation will depend on available battery data source
-// 	status := "Unknown"
-// 	capacity := "Unknown"
-// 	percentage := "Unknown"
-// 	manufacturer := "Unknown"
-// 	model := "Unknown"
-
-// 	// Retrieve battery information from system files (example: /sys/class/power_supply/BAT0/)
-// 	batteryPath := "/sys/class/power_supply/BAT0/"
-// 	statusBytes, err := os.ReadFile(batteryPath + "status")
-// 	if err == nil {
-// 		status = strings.TrimSpace(string(statusBytes))
-// 	}
-// 	capacityBytes, err := os.ReadFile(batteryPath + "energy_full")
-// 	if err == nil {
-// 		capacity = strings.TrimSpace(string(capacityBytes)) + " µWh"
-// 	}
-// 	percentageBytes, err := os.ReadFile(batteryPath + "capacity")
-// 	if err == nil {
-// 		percentage = strings.TrimSpace(string(percentageBytes)) + "%"
-// 	}
-// 	manufacturerBytes, err := os.ReadFile(batteryPath + "manufacturer")
-// 	if err == nil {
-// 		manufacturer = strings.TrimSpace(string(manufacturerBytes))
-// 	}
-// 	modelBytes, err := os.ReadFile(batteryPath + "model_name")
-// 	if err == nil {
-// 		model = strings.TrimSpace(string(modelBytes))
-// 	}
-
-// 	return helper.BatteryInfo{
-// 		Status:       status,
-// 		Capacity:     capacity,
-// 		Percentage:   percentage,
-// 		Manufacturer: manufacturer,
-// 		Model:        model,
-// 	}
-// }
-
 // Helper function to get Peripherals information
-func getPeripheralsInfo() helper.PeripheralInfo {
+func getPeripheralsInfo(ctx context.Context) helper.PeripheralInfo {
 	var peripherals helper.PeripheralInfo
 
 	// Connected devices (using xinput for example)
-	connectedDevicesOutput, err := exec.Command("xinput", "--list", "--name-only").Output()
+	connectedDevicesOutput, err := runCommand(ctx, "xinput", "--list", "--name-only")
 	if err == nil {
 		peripherals.ConnectedDevices = strings.Split(strings.TrimSpace(string(connectedDevicesOutput)), "\n")
 	}
 
-	// USB devices
-	usbDevicesOutput, err := exec.Command("lsusb").Output()
-	if err == nil {
-		usbLines := strings.Split(strings.TrimSpace(string(usbDevicesOutput)), "\n")
-		for _, line := range usbLines {
-			parts := strings.Fields(line)
-			if len(parts) > 5 {
-				vendorID := parts[5][:4]
-				productID := parts[5][5:]
-				vendor := parts[6]
-				name := strings.Join(parts[7:], " ")
-				peripherals.USBDevices = append(peripherals.USBDevices, helper.USBDeviceInfo{
-					Name:      name,
-					Vendor:    vendor,
-					ProductID: productID,
-					VendorID:  vendorID,
-				})
-			}
-		}
-	}
-
 	// Audio devices (using aplay -l for example)
-	audioDevicesOutput, err := exec.Command("aplay", "-l").Output()
+	audioDevicesOutput, err := runCommand(ctx, "aplay", "-l")
 	if err == nil {
 		audioLines := strings.Split(strings.TrimSpace(string(audioDevicesOutput)), "\n")
 		for _, line := range audioLines {
@@ -556,7 +391,7 @@ func getPeripheralsInfo() helper.PeripheralInfo {
 	}
 
 	// Printer details (using lpstat -p for example)
-	printerOutput, err := exec.Command("lpstat", "-p").Output()
+	printerOutput, err := runCommand(ctx, "lpstat", "-p")
 	if err == nil {
 		printerLines := strings.Split(strings.TrimSpace(string(printerOutput)), "\n")
 		peripherals.PrinterDetails = printerLines
@@ -566,17 +401,17 @@ func getPeripheralsInfo() helper.PeripheralInfo {
 }
 
 // Helper function to get Software information
-func getSoftwareInfo() helper.SoftwareInfo {
-	osDetails := getOSDetails()
-	desktopEnvironment := getDesktopEnvironment()
-	windowManager := getWindowManager()
-	wmTheme := getWMTheme()
-	gtkTheme := getGTKTheme()
-	iconsTheme := getIconsTheme()
-	font := getFont()
-	browsers := getInstalledBrowsers()
-	processes := getRunningProcesses()
-	startupPrograms := getStartupPrograms()
+func getSoftwareInfo(ctx context.Context, fsys fs.FS) (helper.SoftwareInfo, error) {
+	osDetails := getOSDetails(fsys)
+	desktopEnvironment := getDesktopEnvironment(ctx, fsys)
+	windowManager := getWindowManager(ctx, fsys)
+	wmTheme := getWMTheme(ctx)
+	gtkTheme := getGTKTheme(ctx)
+	iconsTheme := getIconsTheme(ctx)
+	font := getFont(ctx)
+	browsers := getInstalledBrowsers(ctx)
+	processes, err := getRunningProcesses(ctx)
+	startupPrograms := getStartupPrograms(fsys)
 
 	return helper.SoftwareInfo{
 		OSDetails:          osDetails,
@@ -589,17 +424,17 @@ func getSoftwareInfo() helper.SoftwareInfo {
 		Browser:            browsers,
 		RunningProcesses:   processes,
 		StartupPrograms:    startupPrograms,
-	}
+	}, err
 }
 
 // Helper function to get installed browsers and their versions
-func getInstalledBrowsers() []helper.BrowserInfo {
+func getInstalledBrowsers(ctx context.Context) []helper.BrowserInfo {
 	// List of known browser executables (add more as needed)
 	browsers := []string{"firefox", "google-chrome", "chromium", "brave", "opera"}
 
 	var browserInfos []helper.BrowserInfo
 	for _, browser := range browsers {
-		output, err := exec.Command(browser, "--version").Output()
+		output, err := runCommand(ctx, browser, "--version")
 		if err == nil {
 			versionInfo := strings.TrimSpace(string(output))
 			parts := strings.Fields(versionInfo)
@@ -612,17 +447,23 @@ func getInstalledBrowsers() []helper.BrowserInfo {
 	return browserInfos
 }
 
-// Helper function to get running processes information
-func getRunningProcesses() []helper.ProcessInfo {
+// Helper function to get running processes information, busiest first. The
+// "processes" setting of the software collector limits how many are listed.
+func getRunningProcesses(ctx context.Context) ([]helper.ProcessInfo, error) {
+	limit := helper.CollectorSettings(ctx).Int("processes", 0)
+
 	// Use ps command to get processes info
-	output, err := exec.Command("ps", "axo", "pid,comm,pcpu,pmem", "--sort=-pcpu").Output()
+	output, err := runCommand(ctx, "ps", "axo", "pid,comm,pcpu,pmem", "--sort=-pcpu")
 	if err != nil {
-		panic("Cannot execute ps command")
+		return nil, err
 	}
 
 	lines := strings.Split(string(output), "\n")
 	var processes []helper.ProcessInfo
 	for _, line := range lines[1:] { // Skip the header
+		if limit > 0 && len(processes) == limit {
+			break
+		}
 		fields := strings.Fields(line)
 		if len(fields) < 4 {
 			continue
@@ -641,36 +482,48 @@ func getRunningProcesses() []helper.ProcessInfo {
 		})
 	}
 
-	return processes
+	return processes, nil
 }
 
 // Helper function to get startup programs
-func getStartupPrograms() []helper.StartupProgram {
+func getStartupPrograms(fsys fs.FS) []helper.StartupProgram {
 	// This implementation will vary based on the desktop environment and OS
 
-	// Example for .desktop files in autostart directory
-	autostartDir := filepath.Join(os.Getenv("HOME"), ".config", "autostart")
-	files, err := os.ReadDir(autostartDir)
-	if err != nil {
-		return nil
+	// Example for .desktop files in autostart directories. $HOME is that of the
+	// running system, so below another root those of every home are read.
+	var autostartDirs []string
+	if helper.IsLive(fsys) {
+		autostartDirs = append(autostartDirs, path.Join(strings.TrimPrefix(os.Getenv("HOME"), "/"), ".config", "autostart"))
+	} else {
+		homes, _ := fs.ReadDir(fsys, "home")
+		for _, home := range homes {
+			autostartDirs = append(autostartDirs, path.Join("home", home.Name(), ".config", "autostart"))
+		}
+		autostartDirs = append(autostartDirs, "root/.config/autostart")
 	}
 
 	var startupPrograms []helper.StartupProgram
-	for _, file := range files {
-		if filepath.Ext(file.Name()) == ".desktop" {
-			// Reading .desktop file content
-			content, err := os.ReadFile(filepath.Join(autostartDir, file.Name()))
-			if err == nil {
-				var name, command string
-				for _, line := range strings.Split(string(content), "\n") {
-					if strings.HasPrefix(line, "Name=") {
-						name = strings.TrimSpace(strings.TrimPrefix(line, "Name="))
-					}
-					if strings.HasPrefix(line, "Exec=") {
-						command = strings.TrimSpace(strings.TrimPrefix(line, "Exec="))
+	for _, autostartDir := range autostartDirs {
+		files, err := fs.ReadDir(fsys, autostartDir)
+		if err != nil {
+			continue
+		}
+		for _, file := range files {
+			if path.Ext(file.Name()) == ".desktop" {
+				// Reading .desktop file content
+				content, err := fs.ReadFile(fsys, path.Join(autostartDir, file.Name()))
+				if err == nil {
+					var name, command string
+					for _, line := range strings.Split(string(content), "\n") {
+						if strings.HasPrefix(line, "Name=") {
+							name = strings.TrimSpace(strings.TrimPrefix(line, "Name="))
+						}
+						if strings.HasPrefix(line, "Exec=") {
+							command = strings.TrimSpace(strings.TrimPrefix(line, "Exec="))
+						}
 					}
+					startupPrograms = append(startupPrograms, helper.StartupProgram{Name: name, Command: command})
 				}
-				startupPrograms = append(startupPrograms, helper.StartupProgram{Name: name, Command: command})
 			}
 		}
 	}
@@ -679,55 +532,65 @@ func getStartupPrograms() []helper.StartupProgram {
 }
 
 // Helper function to get Window Manager theme
-func getWMTheme() string {
+func getWMTheme(ctx context.Context) string {
 	// Assuming `gsettings` or `xfconf-query` can be used, this is dependent on the environment
-	output, err := exec.Command("gsettings", "get", "org.gnome.desktop.wm.preferences", "theme").Output()
+	output, err := runCommand(ctx, "gsettings", "get", "org.gnome.desktop.wm.preferences", "theme")
 	if err != nil {
-		return "Unknown"
+		return ""
 	}
 	return strings.TrimSpace(string(output))
 }
 
 // Helper function to get GTK theme
-func getGTKTheme() string {
-	output, err := exec.Command("gsettings", "get", "org.gnome.desktop.interface", "gtk-theme").Output()
+func getGTKTheme(ctx context.Context) string {
+	output, err := runCommand(ctx, "gsettings", "get", "org.gnome.desktop.interface", "gtk-theme")
 	if err != nil {
-		return "Unknown"
+		return ""
 	}
 	return strings.TrimSpace(string(output))
 }
 
 // Helper function to get Icons theme
-func getIconsTheme() string {
-	output, err := exec.Command("gsettings", "get", "org.gnome.desktop.interface", "icon-theme").Output()
+func getIconsTheme(ctx context.Context) string {
+	output, err := runCommand(ctx, "gsettings", "get", "org.gnome.desktop.interface", "icon-theme")
 	if err != nil {
-		return "Unknown"
+		return ""
 	}
 	return strings.TrimSpace(string(output))
 }
 
 // Helper function to get the font used in the system
-func getFont() string {
-	output, err := exec.Command("gsettings", "get", "org.gnome.desktop.interface", "font-name").Output()
+func getFont(ctx context.Context) string {
+	output, err := runCommand(ctx, "gsettings", "get", "org.gnome.desktop.interface", "font-name")
 	if err != nil {
-		return "Unknown"
+		return ""
 	}
 	return strings.TrimSpace(string(output))
 }
 
 // Helper function to get OS details
-func getOSDetails() string {
+func getOSDetails(fsys fs.FS) string {
+	// Below another root the distribution is only known from os-release
+	if !helper.IsLive(fsys) {
+		release := readOSRelease(fsys)
+		return strings.TrimSpace(release["ID"] + " " + release["VERSION_ID"])
+	}
+
 	platform, _, version, err := host.PlatformInformation()
 	if err != nil {
-		return "Unknown"
+		return ""
 	}
 	return fmt.Sprintf("%s %s", platform, version)
 }
 
 // Helper function to get Desktop Environment information
-func getDesktopEnvironment() string {
+func getDesktopEnvironment(ctx context.Context, fsys fs.FS) string {
+	// The session environment is that of the running system
+	if !helper.IsLive(fsys) {
+		return ""
+	}
 	de := os.Getenv("XDG_CURRENT_DESKTOP")
-	version, err := exec.Command("sh", "-c", "echo $XDG_SESSION_DESKTOP").Output()
+	version, err := runCommand(ctx, "sh", "-c", "echo $XDG_SESSION_DESKTOP")
 	if err != nil {
 		return de
 	}
@@ -735,9 +598,13 @@ func getDesktopEnvironment() string {
 }
 
 // Helper function to get Window Manager information
-func getWindowManager() string {
+func getWindowManager(ctx context.Context, fsys fs.FS) string {
+	// The session environment is that of the running system
+	if !helper.IsLive(fsys) {
+		return ""
+	}
 	wm := os.Getenv("XDG_SESSION_DESKTOP")
-	version, err := exec.Command("sh", "-c", "wmctrl -m | grep 'Name|Version'").Output()
+	version, err := runCommand(ctx, "sh", "-c", "wmctrl -m | grep 'Name|Version'")
 	if err != nil {
 		return wm
 	}
@@ -745,75 +612,43 @@ func getWindowManager() string {
 }
 
 // Helper function to get system performance information
-func getPerformanceInfo() helper.PerformanceInfo {
-	cpuUsage, perCoreUsage := getCPUUsage()
-	memoryUsage, perAppMemoryUsage := getMemoryUsage()
-
-	return helper.PerformanceInfo{
-		CPUUsage:          cpuUsage,
-		PerCoreUsage:      perCoreUsage,
-		MemoryUsage:       memoryUsage,
-		PerAppMemoryUsage: perAppMemoryUsage,
-	}
-}
-
-// Helper function to get CPU usage information
-func getCPUUsage() (float64, []float64) {
-	// Use top command to get overall CPU usage and per-core usage
-	output, err := exec.Command("top", "-bn1").Output()
-	if err != nil {
-		panic("Cannot execute top command")
-	}
+func getPerformanceInfo(ctx context.Context, fsys fs.FS) (helper.PerformanceInfo, error) {
+	performance, cpuErr := getCPUUsage(ctx, fsys)
+	memoryUsage, perAppMemoryUsage, memoryErr := getMemoryUsage(ctx, fsys)
 
-	lines := strings.Split(string(output), "\n")
-	var cpuUsage float64
-	var perCoreUsage []float64
-
-	for _, line := range lines {
-		if strings.HasPrefix(line, "%Cpu(s):") {
-			// Overall CPU usage
-			parts := strings.Fields(line)
-			usage, _ := strconv.ParseFloat(parts[1], 64)
-			cpuUsage = usage
-		} else if strings.HasPrefix(line, "%Cpu") {
-			// Per-core usage
-			parts := strings.Fields(line)
-			usage, _ := strconv.ParseFloat(parts[1], 64)
-			perCoreUsage = append(perCoreUsage, usage)
-		}
-	}
-
-	return cpuUsage, perCoreUsage
+	performance.MemoryUsage = memoryUsage
+	performance.PerAppMemoryUsage = perAppMemoryUsage
+	return performance, errors.Join(cpuErr, memoryErr)
 }
 
-// Helper function to get memory usage information
-func getMemoryUsage() (helper.MemoryUsageInfo, []helper.AppMemoryUsage) {
-	// Use free command to get overall memory usage
-	freeOutput, err := exec.Command("free", "-h").Output()
+// Helper function to get memory usage information. The "processes" setting of
+// the performance collector limits how many applications are listed.
+func getMemoryUsage(ctx context.Context, fsys fs.FS) (helper.MemoryUsageInfo, []helper.AppMemoryUsage, error) {
+	limit := helper.CollectorSettings(ctx).Int("processes", 0)
+
+	// Use /proc/meminfo to get overall memory usage
+	meminfo, err := readMeminfo(fsys)
 	if err != nil {
-		panic("Cannot execute free command")
+		return helper.MemoryUsageInfo{}, nil, err
 	}
-
-	freeLines := strings.Split(string(freeOutput), "\n")
-	var memoryUsageInfo helper.MemoryUsageInfo
-	for _, line := range freeLines {
-		if strings.HasPrefix(line, "Mem:") {
-			parts := strings.Fields(line)
-			memoryUsageInfo.Total = parts[1]
-			memoryUsageInfo.TotalUsed = parts[2]
-			memoryUsageInfo.Free = parts[3]
-		}
+	memoryUsageInfo := helper.MemoryUsageInfo{
+		Total:     meminfo["MemTotal"],
+		TotalUsed: meminfo.used(),
+		Free:      meminfo["MemFree"],
 	}
 
 	// Use ps command to get per-application memory usage
-	psOutput, err := exec.Command("ps", "axo", "pid,comm,%mem", "--sort=-%mem").Output()
+	psOutput, err := runCommand(ctx, "ps", "axo", "pid,comm,%mem", "--sort=-%mem")
 	if err != nil {
-		panic("Cannot execute ps command")
+		return memoryUsageInfo, nil, err
 	}
 
 	psLines := strings.Split(string(psOutput), "\n")
 	var perAppMemoryUsage []helper.AppMemoryUsage
 	for _, line := range psLines[1:] { // Skip the header
+		if limit > 0 && len(perAppMemoryUsage) == limit {
+			break
+		}
 		fields := strings.Fields(line)
 		if len(fields) < 3 {
 			continue
@@ -830,88 +665,81 @@ func getMemoryUsage() (helper.MemoryUsageInfo, []helper.AppMemoryUsage) {
 		})
 	}
 
-	return memoryUsageInfo, perAppMemoryUsage
+	return memoryUsageInfo, perAppMemoryUsage, nil
 }
 
 // Helper function to get package management information
-func getPackageManagementInfo() helper.PackageManagementInfo {
-	packageCount := getPackageCount()
-	availableUpdates := getAvailableUpdates()
-	packageManagers := getPackageManagers()
-	recentlyInstalledPackages := getRecentlyInstalledPackages()
+func getPackageManagementInfo(ctx context.Context, fsys fs.FS) (helper.PackageManagementInfo, error) {
+	packageCount, countErr := getPackageCount(ctx)
+	availableUpdates, updatesErr := getAvailableUpdates(ctx)
+	packageManagers := getPackageManagers(fsys)
+	recentlyInstalledPackages, recentErr := getRecentlyInstalledPackages(fsys)
 
 	return helper.PackageManagementInfo{
 		PackageCount:              packageCount,
 		AvailableUpdates:          availableUpdates,
 		PackageManagers:           packageManagers,
 		RecentlyInstalledPackages: recentlyInstalledPackages,
-	}
+	}, errors.Join(countErr, updatesErr, recentErr)
 }
 
 // Function to get the number of installed packages
-func getPackageCount() int {
-	output, err := exec.Command("dpkg-query", "-f", ".", "-W").Output()
+func getPackageCount(ctx context.Context) (int, error) {
+	output, err := runCommand(ctx, "dpkg-query", "-f", ".", "-W")
 	if err != nil {
-		panic("Cannot execute dpkg-query command")
+		return 0, err
 	}
-	return len(output)
+	return len(output), nil
 }
 
 // Function to get the number of available updates
-func getAvailableUpdates() int {
-	output, err := exec.Command("apt", "list", "--upgradable").Output()
+func getAvailableUpdates(ctx context.Context) (int, error) {
+	output, err := runCommand(ctx, "apt", "list", "--upgradable")
 	if err != nil {
-		panic("Cannot execute apt command to check for updates")
+		return 0, err
 	}
 	lines := strings.Split(string(output), "\n")
-	return len(lines) - 1 // Exclude header line
+	return len(lines) - 1, nil // Exclude header line
 }
 
-// Function to list the used package managers
-func getPackageManagers() []string {
+// Function to list the used package managers, found in /usr/bin or /bin
+func getPackageManagers(fsys fs.FS) []string {
 	// List common package managers on Linux systems
 	packageManagers := []string{}
-	if _, err := exec.LookPath("apt"); err == nil {
-		packageManagers = append(packageManagers, "apt")
-	}
-	if _, err := exec.LookPath("dnf"); err == nil {
-		packageManagers = append(packageManagers, "dnf")
-	}
-	if _, err := exec.LookPath("pacman"); err == nil {
-		packageManagers = append(packageManagers, "pacman")
-	}
-	if _, err := exec.LookPath("yum"); err == nil {
-		packageManagers = append(packageManagers, "yum")
-	}
-	if _, err := exec.LookPath("zypper"); err == nil {
-		packageManagers = append(packageManagers, "zypper")
+	for _, name := range []string{"apt", "dnf", "pacman", "yum", "zypper"} {
+		for _, dir := range []string{"usr/bin", "bin"} {
+			if _, err := fs.Stat(fsys, path.Join(dir, name)); err == nil {
+				packageManagers = append(packageManagers, name)
+				break
+			}
+		}
 	}
 	return packageManagers
 }
 
 // Helper function to get recently installed packages
-func getRecentlyInstalledPackages() []helper.PackageInfo {
-	logFiles := []string{"/var/log/dpkg.log", "/var/log/dpkg.log.1"}
+func getRecentlyInstalledPackages(fsys fs.FS) ([]helper.PackageInfo, error) {
+	logFiles := []string{"var/log/dpkg.log", "var/log/dpkg.log.1"}
 	var packages []helper.PackageInfo
+	var errs []error
 
 	for _, logFile := range logFiles {
-		if _, err := os.Stat(logFile); os.IsNotExist(err) {
+		output, err := fs.ReadFile(fsys, logFile)
+		if errors.Is(err, fs.ErrNotExist) {
 			continue // Skip if the log file does not exist
 		}
-
-		output, err := exec.Command("zgrep", "install ", logFile).Output()
 		if err != nil {
-			fmt.Printf("Warning: Cannot read dpkg logs from %s: %v\n", logFile, err)
+			errs = append(errs, err)
 			continue
 		}
 
 		lines := strings.Split(string(output), "\n")
 		for _, line := range lines {
-			if len(line) == 0 {
+			if !strings.Contains(line, "install ") {
 				continue
 			}
 			parts := strings.Fields(line)
-			if len(parts) >= 4 {
+			if len(parts) >= 5 {
 				packages = append(packages, helper.PackageInfo{
 					Name:          parts[3],
 					Version:       parts[4],
@@ -921,18 +749,17 @@ func getRecentlyInstalledPackages() []helper.PackageInfo {
 		}
 	}
 
-	return packages
+	return packages, errors.Join(errs...)
 }
 
 // Helper function to get other information
-func getOtherInfo() helper.OtherInfo {
-	publicIP := getPublicIP()
-	timezone := getTimezone()
-	locale := getLocale()
-	temperature := getTemperature()
-	systemLanguage := getSystemLanguage()
-	screenResolution := getScreenResolution()
-	diskPartitions := getDiskPartitions()
+func getOtherInfo(ctx context.Context) helper.OtherInfo {
+	publicIP := getPublicIP(ctx)
+	timezone := getTimezone(ctx)
+	locale := getLocale(ctx)
+	temperature := getTemperature(ctx)
+	systemLanguage := getSystemLanguage(ctx)
+	screenResolution := getScreenResolution(ctx)
 
 	return helper.OtherInfo{
 		PublicIP:         publicIP,
@@ -941,79 +768,95 @@ func getOtherInfo() helper.OtherInfo {
 		Temperature:      temperature,
 		SystemLanguage:   systemLanguage,
 		ScreenResolution: screenResolution,
-		DiskPartitions:   diskPartitions,
 	}
 }
 
 // Function to get public IP address
-func getPublicIP() string {
-	output, err := exec.Command("curl", "-s", "ifconfig.me").Output()
+func getPublicIP(ctx context.Context) string {
+	output, err := runCommand(ctx, "curl", "-s", "ifconfig.me")
 	if err != nil {
-		return "Unavailable"
+		return ""
 	}
 	return strings.TrimSpace(string(output))
 }
 
 // Function to get timezone
-func getTimezone() string {
-	output, err := exec.Command("timedatectl", "show", "-p", "Timezone").Output()
+func getTimezone(ctx context.Context) string {
+	output, err := runCommand(ctx, "timedatectl", "show", "-p", "Timezone")
 	if err != nil {
-		return "Unavailable"
+		return ""
+	}
+	_, timezone, found := strings.Cut(string(output), "=")
+	if !found {
+		return ""
 	}
-	return strings.TrimSpace(strings.Split(string(output), "=")[1])
+	return strings.TrimSpace(timezone)
 }
 
 // Function to get locale
-func getLocale() string {
-	output, err := exec.Command("locale", "|", "grep", "LANG=").Output()
+func getLocale(ctx context.Context) string {
+	output, err := runCommand(ctx, "locale", "|", "grep", "LANG=")
 	if err != nil {
-		return "Unavailable"
+		return ""
+	}
+	_, lang, found := strings.Cut(string(output), "=")
+	if !found {
+		return ""
 	}
-	return strings.TrimSpace(strings.Split(string(output), "=")[1])
+	return strings.TrimSpace(lang)
 }
 
 // Function to get temperature readings
-func getTemperature() helper.TemperatureInfo {
+func getTemperature(ctx context.Context) helper.TemperatureInfo {
 	// Example implementation using lm-sensors
-	output, err := exec.Command("sensors").Output()
+	output, err := runCommand(ctx, "sensors")
 	if err != nil {
 		return helper.TemperatureInfo{}
 	}
 
-	var cpuTemp, gpuTemp, moboTemp float64
+	var temperature helper.TemperatureInfo
 	lines := strings.Split(string(output), "\n")
 	for _, line := range lines {
 		if strings.Contains(line, "Core 0:") {
-			fmt.Sscanf(line, "Core 0: +%f°C", &cpuTemp)
+			temperature.CPU = scanTemperature(line, "Core 0: +%f°C")
 		}
 		if strings.Contains(line, "temp1:") && strings.Contains(line, "GPU") {
-			fmt.Sscanf(line, "temp1: +%f°C", &gpuTemp)
+			temperature.GPU = scanTemperature(line, "temp1: +%f°C")
 		}
 		if strings.Contains(line, "temp1:") && strings.Contains(line, "MB") {
-			fmt.Sscanf(line, "temp1: +%f°C", &moboTemp)
+			temperature.Motherboard = scanTemperature(line, "temp1: +%f°C")
 		}
 	}
 
-	return helper.TemperatureInfo{
-		CPU:         cpuTemp,
-		GPU:         gpuTemp,
-		Motherboard: moboTemp,
+	return temperature
+}
+
+// Helper function to parse a sensors reading, returning nil if it doesn't match format
+func scanTemperature(line, format string) *float64 {
+	var celsius float64
+	if _, err := fmt.Sscanf(line, format, &celsius); err != nil {
+		return nil
 	}
+	return &celsius
 }
 
 // Function to get system language
-func getSystemLanguage() string {
-	output, err := exec.Command("locale", "|", "grep", "LANG=").Output()
+func getSystemLanguage(ctx context.Context) string {
+	output, err := runCommand(ctx, "locale", "|", "grep", "LANG=")
 	if err != nil {
-		return "Unavailable"
+		return ""
+	}
+	_, lang, found := strings.Cut(string(output), "=")
+	if !found {
+		return ""
 	}
-	return strings.TrimSpace(strings.Split(string(output), "=")[1])
+	return strings.TrimSpace(lang)
 }
 
 // Function to get screen resolution and monitor details
-func getScreenResolution() []helper.ScreenInfo {
+func getScreenResolution(ctx context.Context) []helper.ScreenInfo {
 	var screens []helper.ScreenInfo
-	output, err := exec.Command("xrandr", "--query").Output()
+	output, err := runCommand(ctx, "xrandr", "--query")
 	if err != nil {
 		return screens
 	}
@@ -1022,6 +865,9 @@ func getScreenResolution() []helper.ScreenInfo {
 	for _, line := range lines {
 		if strings.Contains(line, " connected") {
 			parts := strings.Fields(line)
+			if len(parts) < 5 {
+				continue
+			}
 			model := parts[0]
 			resolution := parts[2]
 			var refreshRate int
@@ -1035,28 +881,3 @@ func getScreenResolution() []helper.ScreenInfo {
 	}
 	return screens
 }
-
-// Function to get disk partitions information
-func getDiskPartitions() []helper.PartitionInfo {
-	var partitions []helper.PartitionInfo
-	output, err := exec.Command("lsblk", "-o", "NAME,FSTYPE,MOUNTPOINT,SIZE,USED,AVAIL").Output()
-	if err != nil {
-		return partitions
-	}
-
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines[1:] {
-		parts := strings.Fields(line)
-		if len(parts) >= 6 {
-			partitions = append(partitions, helper.PartitionInfo{
-				Device:     parts[0],
-				Filesystem: parts[1],
-				MountPoint: parts[2],
-				Size:       parts[3],
-				Used:       parts[4],
-				Available:  parts[5],
-			})
-		}
-	}
-	return partitions
-}
@@ -0,0 +1,118 @@
+package linux
+
+import (
+	"context"
+	"defetch/helper"
+	"io/fs"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// Helper function to open one of the fixture roots in testdata
+func fixture(name string) fs.FS {
+	return helper.RootFS(filepath.Join("testdata", name))
+}
+
+func TestHostInfoBelowRoot(t *testing.T) {
+	tests := []struct {
+		root string
+		want helper.HostInfo
+	}{
+		{"laptop", helper.HostInfo{Hostname: "thinkpad", OSName: "debian", OSVersion: "12", OSID: "debian", OSIDLike: []string{}}},
+		// Without /etc/os-release the copy in /usr/lib is read
+		{"convertible", helper.HostInfo{OSName: "archarm", OSID: "archarm", OSIDLike: []string{"arch"}}},
+	}
+	for _, test := range tests {
+		got, err := getHostInfo(context.Background(), fixture(test.root))
+		if err != nil {
+			t.Fatalf("%s: %v", test.root, err)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %+v, want %+v", test.root, got, test.want)
+		}
+	}
+}
+
+func TestCPUInfo(t *testing.T) {
+	tests := []struct {
+		root string
+		want helper.CPUInfo
+	}{
+		{"laptop", helper.CPUInfo{
+			ModelName: "11th Gen Intel(R) Core(TM) i5-1135G7 @ 2.40GHz",
+			Cores:     2,
+			Threads:   4,
+			Frequency: 2400,
+			CacheSize: 8192,
+			Flags:     "fpu vme de pse tsc msr sse sse2 avx avx2",
+		}},
+		// ARM lists no model name, the device tree names the board instead
+		{"convertible", helper.CPUInfo{ModelName: "Google Kevin", Threads: 2}},
+	}
+	for _, test := range tests {
+		got, err := getCPUInfo(fixture(test.root))
+		if err != nil {
+			t.Fatalf("%s: %v", test.root, err)
+		}
+		got.Architecture = ""
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %+v, want %+v", test.root, got, test.want)
+		}
+	}
+}
+
+func TestMotherboardInfoFromDMI(t *testing.T) {
+	got := getMotherboardInfo(context.Background(), fixture("laptop"))
+	want := helper.MotherboardInfo{
+		Manufacturer: "LENOVO",
+		Model:        "20XXS3HC00",
+		BIOSVersion:  "N32ET75W (1.51 )",
+		SerialNumber: "L1HF12345678",
+	}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestStartupPrograms(t *testing.T) {
+	// Below another root the $HOME of the running system is not used
+	t.Setenv("HOME", "/home/bob")
+
+	got := getStartupPrograms(fixture("laptop"))
+	want := []helper.StartupProgram{{Name: "Syncthing", Command: "syncthing serve --no-browser"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestDesktopOtherRoot(t *testing.T) {
+	t.Setenv("XDG_CURRENT_DESKTOP", "GNOME")
+	t.Setenv("XDG_SESSION_DESKTOP", "gnome")
+
+	// The session of the running system says nothing about the one below the root
+	ctx := context.Background()
+	if got := getDesktopEnvironment(ctx, fixture("laptop")); got != "" {
+		t.Errorf("got desktop environment %q", got)
+	}
+	if got := getWindowManager(ctx, fixture("laptop")); got != "" {
+		t.Errorf("got window manager %q", got)
+	}
+}
+
+func TestRecentlyInstalledPackages(t *testing.T) {
+	got, err := getRecentlyInstalledPackages(fixture("laptop"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []helper.PackageInfo{{Name: "htop:amd64", Version: "<none>", InstalledDate: "2024-05-02 10:15:02"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	// Systems without dpkg have no log, which is not an error
+	got, err = getRecentlyInstalledPackages(fixture("convertible"))
+	if err != nil || got != nil {
+		t.Errorf("got %+v, %v without a dpkg log", got, err)
+	}
+}
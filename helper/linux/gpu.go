@@ -0,0 +1,83 @@
+package linux
+
+import (
+	"defetch/helper"
+	"defetch/helper/ids"
+	"errors"
+	"io/fs"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// drmCardPattern matches DRM cards, leaving out their connectors like card0-HDMI-A-1
+var drmCardPattern = regexp.MustCompile(`^card\d+$`)
+
+// drmDir lists the graphics cards and their connectors
+const drmDir = "sys/class/drm"
+
+// Helper function to get every GPU from /sys/class/drm
+func getGPUInfo(fsys fs.FS) ([]helper.GPUInfo, error) {
+	cards, err := fs.ReadDir(fsys, drmDir)
+	if err != nil {
+		return nil, err
+	}
+
+	pci := ids.PCI(fsys)
+	var gpus []helper.GPUInfo
+	for _, card := range cards {
+		if !drmCardPattern.MatchString(card.Name()) {
+			continue
+		}
+		device := path.Join(drmDir, card.Name(), "device")
+
+		gpu := helper.GPUInfo{
+			Card:              card.Name(),
+			VendorID:          pciID(readSysfs(fsys, path.Join(device, "vendor"))),
+			DeviceID:          pciID(readSysfs(fsys, path.Join(device, "device"))),
+			SubsystemVendorID: pciID(readSysfs(fsys, path.Join(device, "subsystem_vendor"))),
+			SubsystemDeviceID: pciID(readSysfs(fsys, path.Join(device, "subsystem_device"))),
+			MemorySize:        parseBytes(readSysfs(fsys, path.Join(device, "mem_info_vram_total"))),
+			Primary:           readSysfs(fsys, path.Join(device, "boot_vga")) == "1",
+		}
+		// The device link points at the PCI device, e.g. ../../../0000:01:00.0
+		if target, err := helper.ReadLink(fsys, device); err == nil {
+			gpu.PCIAddress = path.Base(target)
+		}
+		if driver, err := helper.ReadLink(fsys, path.Join(device, "driver")); err == nil {
+			gpu.Driver = path.Base(driver)
+			gpu.DriverVersion = readSysfs(fsys, path.Join("sys/module", gpu.Driver, "version"))
+		}
+
+		gpu.Vendor = pci.Vendor(gpu.VendorID)
+		gpu.ModelName = pci.Device(gpu.VendorID, gpu.DeviceID)
+		gpu.Subsystem = pci.Subsystem(gpu.VendorID, gpu.DeviceID, gpu.SubsystemVendorID, gpu.SubsystemDeviceID)
+		// Devices that are not on PCI, like those of many ARM boards, are named
+		// after their driver. PCI devices missing from pci.ids stay unnamed.
+		if gpu.VendorID == "" && gpu.DeviceID == "" {
+			gpu.ModelName = gpu.Driver
+		}
+
+		gpus = append(gpus, gpu)
+	}
+
+	if len(gpus) == 0 {
+		return nil, errors.New("no GPU found in /sys/class/drm")
+	}
+
+	// Without boot_vga, e.g. on systems without VGA, the first card is the primary one
+	primary := false
+	for _, gpu := range gpus {
+		primary = primary || gpu.Primary
+	}
+	if !primary {
+		gpus[0].Primary = true
+	}
+
+	return gpus, nil
+}
+
+// Helper function to turn a sysfs ID such as 0x10de into the pci.ids form 10de
+func pciID(value string) string {
+	return strings.ToLower(strings.TrimPrefix(value, "0x"))
+}
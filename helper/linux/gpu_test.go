@@ -0,0 +1,38 @@
+package linux
+
+import (
+	"testing"
+)
+
+func TestGPUInfo(t *testing.T) {
+	gpus, err := getGPUInfo(fixture("laptop"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Connectors like card0-eDP-1 are no GPUs of their own
+	if len(gpus) != 1 {
+		t.Fatalf("got %d GPUs, want 1: %+v", len(gpus), gpus)
+	}
+
+	gpu := gpus[0]
+	if gpu.Card != "card0" || gpu.PCIAddress != "0000:00:02.0" || !gpu.Primary {
+		t.Errorf("got card %q at %q, primary %v", gpu.Card, gpu.PCIAddress, gpu.Primary)
+	}
+	if gpu.VendorID != "8086" || gpu.DeviceID != "9a49" || gpu.SubsystemVendorID != "17aa" || gpu.SubsystemDeviceID != "22d8" {
+		t.Errorf("got IDs %s:%s %s:%s", gpu.VendorID, gpu.DeviceID, gpu.SubsystemVendorID, gpu.SubsystemDeviceID)
+	}
+	if gpu.Vendor != "Intel Corporation" || gpu.Driver != "i915" {
+		t.Errorf("got vendor %q and driver %q", gpu.Vendor, gpu.Driver)
+	}
+	// The fixture has no pci.ids and the embedded one doesn't know the device,
+	// which leaves a PCI device unnamed rather than named after its driver
+	if gpu.ModelName != "" {
+		t.Errorf("got model %q, want none", gpu.ModelName)
+	}
+}
+
+func TestGPUInfoWithoutDRM(t *testing.T) {
+	if gpus, err := getGPUInfo(fixture("convertible")); err == nil {
+		t.Errorf("got %+v and no error without /sys/class/drm", gpus)
+	}
+}
@@ -0,0 +1,392 @@
+package linux
+
+import (
+	"context"
+	"defetch/helper"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"runtime"
+	"sort"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// diskHealth is the value of the storage-health collector: the health of each
+// disk by kernel name, which GetLinuxInfo adds to the disks of the storage
+// section
+type diskHealth map[string]*helper.DiskHealth
+
+const (
+	// nvmeAdminCmd is NVME_IOCTL_ADMIN_CMD, _IOWR('N', 0x41, struct nvme_admin_cmd)
+	nvmeAdminCmd = 0xc0484e41
+	// nvmeGetLogPage is the admin opcode reading a log page, and nvmeSmartLog
+	// the page of the SMART / health information
+	nvmeGetLogPage = 0x02
+	nvmeSmartLog   = 0x02
+
+	// sgIO is the SG_IO ioctl of the SCSI generic driver, which SATA disks
+	// answer through the ATA PASS-THROUGH command of SAT
+	sgIO           = 0x2285
+	sgDxferFromDev = -3
+
+	// smartDataSize is the size of the NVMe SMART log and of ATA SMART data
+	smartDataSize = 512
+
+	// ioctlTimeout is the time in ms a disk gets to answer
+	ioctlTimeout = 5000
+)
+
+// ATA SMART attributes that are reported
+const (
+	ataReallocatedSectors = 5
+	ataPowerOnHours       = 9
+	ataPowerCycles        = 12
+	ataUncorrectable      = 187
+	ataAirflowTemperature = 190
+	ataTemperature        = 194
+)
+
+// nvmeAdminCommand is struct nvme_admin_cmd of <linux/nvme_ioctl.h>
+type nvmeAdminCommand struct {
+	Opcode      uint8
+	Flags       uint8
+	_           uint16
+	NSID        uint32
+	CDW2, CDW3  uint32
+	Metadata    uint64
+	Addr        uint64
+	MetadataLen uint32
+	DataLen     uint32
+	CDW10       uint32
+	CDW11       uint32
+	CDW12       uint32
+	CDW13       uint32
+	CDW14       uint32
+	CDW15       uint32
+	TimeoutMs   uint32
+	Result      uint32
+}
+
+// sgIOHeader is struct sg_io_hdr of <scsi/sg.h>
+type sgIOHeader struct {
+	InterfaceID    int32
+	DxferDirection int32
+	CmdLen         uint8
+	MxSbLen        uint8
+	IovecCount     uint16
+	DxferLen       uint32
+	Dxferp         *byte
+	Cmdp           *byte
+	Sbp            *byte
+	Timeout        uint32
+	Flags          uint32
+	PackID         int32
+	UsrPtr         *byte
+	Status         uint8
+	MaskedStatus   uint8
+	MsgStatus      uint8
+	SbLenWr        uint8
+	HostStatus     uint16
+	DriverStatus   uint16
+	Resid          int32
+	Duration       uint32
+	Info           uint32
+}
+
+// Helper function to get the health of the NVMe and SATA disks. The disks are
+// asked directly on the running system, which needs root or the disk group,
+// and smartctl is run for those that cannot be. Disks that neither way gives
+// access to are left out without an error.
+func getDiskHealth(ctx context.Context, fsys fs.FS) (diskHealth, error) {
+	entries, err := fs.ReadDir(fsys, blockDir)
+	if err != nil {
+		return nil, err
+	}
+
+	health := make(diskHealth)
+	var errs []error
+	for _, entry := range entries {
+		name := entry.Name()
+		var read func(device string) (*helper.DiskHealth, error)
+		switch {
+		case strings.HasPrefix(name, "nvme"):
+			read = readNVMeHealth
+		case strings.HasPrefix(name, "sd"), strings.HasPrefix(name, "hd"):
+			read = readATAHealth
+		default:
+			continue
+		}
+
+		var info *helper.DiskHealth
+		if helper.IsLive(fsys) {
+			info, _ = read("/dev/" + name)
+		}
+		if info == nil {
+			info, err = smartctlHealth(ctx, name)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			}
+		}
+		if info != nil {
+			health[name] = info
+		}
+	}
+	return health, errors.Join(errs...)
+}
+
+// Helper function to add the health of each disk to the storage section.
+// Disks the storage collector did not report get an entry of their own.
+func addDiskHealth(sysInfo *helper.SysInfo, health diskHealth) {
+	for i := range sysInfo.Storage {
+		if info, ok := health[sysInfo.Storage[i].Device]; ok {
+			sysInfo.Storage[i].Health = info
+			delete(health, sysInfo.Storage[i].Device)
+		}
+	}
+
+	var missing []string
+	for name := range health {
+		missing = append(missing, name)
+	}
+	sort.Strings(missing)
+	for _, name := range missing {
+		sysInfo.Storage = append(sysInfo.Storage, helper.StorageInfo{Device: name, Health: health[name]})
+	}
+}
+
+// Helper function to read the SMART / health log of an NVMe disk with an admin
+// command
+func readNVMeHealth(device string) (*helper.DiskHealth, error) {
+	fd, err := unix.Open(device, unix.O_RDONLY|unix.O_NONBLOCK|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer unix.Close(fd)
+
+	data := make([]byte, smartDataSize)
+	cmd := nvmeAdminCommand{
+		Opcode:    nvmeGetLogPage,
+		NSID:      0xffffffff, // The log of the whole controller
+		Addr:      uint64(uintptr(unsafe.Pointer(&data[0]))),
+		DataLen:   smartDataSize,
+		CDW10:     (smartDataSize/4-1)<<16 | nvmeSmartLog,
+		TimeoutMs: ioctlTimeout,
+	}
+	status, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(fd), nvmeAdminCmd, uintptr(unsafe.Pointer(&cmd)))
+	runtime.KeepAlive(data)
+	if errno != 0 {
+		return nil, fmt.Errorf("%s: NVMe admin command: %w", device, errno)
+	}
+	// The ioctl returns the status field of the completion, which is 0 on
+	// success, while cmd.Result is the command specific dword 0
+	if status != 0 {
+		return nil, fmt.Errorf("%s: NVMe admin command failed with status %#x", device, status)
+	}
+	return parseNVMeSmartLog(data)
+}
+
+// Helper function to read the SMART data of a SATA disk with ATA PASS-THROUGH
+func readATAHealth(device string) (*helper.DiskHealth, error) {
+	fd, err := unix.Open(device, unix.O_RDONLY|unix.O_NONBLOCK|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer unix.Close(fd)
+
+	// ATA PASS-THROUGH (16) of SMART READ DATA: PIO data-in of one 512-byte
+	// block, with the SMART signature in LBA mid and high
+	cdb := []byte{
+		0x85, 4 << 1, 0x0e, // Opcode, protocol, transfer from the device in blocks of the sector count
+		0, 0xd0, // Features: READ DATA
+		0, 1, // Sector count
+		0, 0, // LBA low
+		0, 0x4f, // LBA mid
+		0, 0xc2, // LBA high
+		0,    // Device
+		0xb0, // Command: SMART
+		0,
+	}
+	data := make([]byte, smartDataSize)
+	sense := make([]byte, 32)
+	hdr := sgIOHeader{
+		InterfaceID:    'S',
+		DxferDirection: sgDxferFromDev,
+		CmdLen:         uint8(len(cdb)),
+		MxSbLen:        uint8(len(sense)),
+		DxferLen:       smartDataSize,
+		Dxferp:         &data[0],
+		Cmdp:           &cdb[0],
+		Sbp:            &sense[0],
+		Timeout:        ioctlTimeout,
+	}
+	_, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(fd), sgIO, uintptr(unsafe.Pointer(&hdr)))
+	runtime.KeepAlive(data)
+	if errno != 0 {
+		return nil, fmt.Errorf("%s: SG_IO: %w", device, errno)
+	}
+	if hdr.Status != 0 || hdr.HostStatus != 0 || hdr.DriverStatus != 0 {
+		return nil, fmt.Errorf("%s: ATA PASS-THROUGH failed with status %#x, host %#x, driver %#x",
+			device, hdr.Status, hdr.HostStatus, hdr.DriverStatus)
+	}
+	return parseATASmartData(data)
+}
+
+// Helper function to parse the SMART / health log of an NVMe disk. Counters
+// are 128-bit, of which the lower half is read.
+func parseNVMeSmartLog(data []byte) (*helper.DiskHealth, error) {
+	if len(data) < smartDataSize {
+		return nil, fmt.Errorf("NVMe SMART log of %d bytes, want %d", len(data), smartDataSize)
+	}
+
+	health := &helper.DiskHealth{
+		Source:         "nvme",
+		PercentageUsed: ptr(int(data[5])),
+		PowerCycles:    ptr(binary.LittleEndian.Uint64(data[112:])),
+		PowerOnHours:   ptr(binary.LittleEndian.Uint64(data[128:])),
+		MediaErrors:    ptr(binary.LittleEndian.Uint64(data[160:])),
+	}
+	// The composite temperature is in kelvin, 0 when not reported
+	if kelvin := binary.LittleEndian.Uint16(data[1:]); kelvin > 0 {
+		health.Temperature = ptr(float64(kelvin) - 273)
+	}
+	return health, nil
+}
+
+// Helper function to parse the attribute table of ATA SMART data, 30 entries
+// of 12 bytes: ID, flags, value, worst, a 48-bit raw value and a reserved byte
+func parseATASmartData(data []byte) (*helper.DiskHealth, error) {
+	if len(data) < smartDataSize {
+		return nil, fmt.Errorf("ATA SMART data of %d bytes, want %d", len(data), smartDataSize)
+	}
+	var sum byte
+	for _, b := range data[:smartDataSize] {
+		sum += b
+	}
+	if sum != 0 {
+		return nil, errors.New("ATA SMART data with a wrong checksum")
+	}
+
+	raw := make(map[byte]uint64)
+	for i := 2; i+12 <= 2+30*12; i += 12 {
+		entry := data[i : i+12]
+		if entry[0] == 0 {
+			continue
+		}
+		var value uint64
+		for j := 10; j >= 5; j-- {
+			value = value<<8 | uint64(entry[j])
+		}
+		raw[entry[0]] = value
+	}
+	return ataHealth("ata", raw), nil
+}
+
+// Helper function to pick the reported figures from the raw values of ATA
+// SMART attributes by ID
+func ataHealth(source string, raw map[byte]uint64) *helper.DiskHealth {
+	health := &helper.DiskHealth{Source: source}
+	if value, ok := raw[ataReallocatedSectors]; ok {
+		health.ReallocatedSectors = ptr(value)
+	}
+	// Some disks keep minutes or milliseconds in the upper bytes
+	if value, ok := raw[ataPowerOnHours]; ok {
+		health.PowerOnHours = ptr(value & 0xffffffff)
+	}
+	if value, ok := raw[ataPowerCycles]; ok {
+		health.PowerCycles = ptr(value)
+	}
+	if value, ok := raw[ataUncorrectable]; ok {
+		health.MediaErrors = ptr(value)
+	}
+	// The lowest byte holds the current temperature, higher ones the extremes
+	for _, id := range []byte{ataTemperature, ataAirflowTemperature} {
+		if value, ok := raw[id]; ok && health.Temperature == nil {
+			health.Temperature = ptr(float64(value & 0xff))
+		}
+	}
+	return health
+}
+
+// smartctlOutput holds the parts of `smartctl --json -a` that are reported
+type smartctlOutput struct {
+	Temperature *struct {
+		Current float64 `json:"current"`
+	} `json:"temperature"`
+	PowerOnTime *struct {
+		Hours uint64 `json:"hours"`
+	} `json:"power_on_time"`
+	PowerCycleCount *uint64 `json:"power_cycle_count"`
+	NVMeLog         *struct {
+		PercentageUsed int    `json:"percentage_used"`
+		MediaErrors    uint64 `json:"media_errors"`
+	} `json:"nvme_smart_health_information_log"`
+	ATAAttributes *struct {
+		Table []struct {
+			ID  byte `json:"id"`
+			Raw struct {
+				Value uint64 `json:"value"`
+			} `json:"raw"`
+		} `json:"table"`
+	} `json:"ata_smart_attributes"`
+}
+
+// Helper function to get the health of a disk from smartctl. A missing
+// smartctl, or one that cannot open the disk, leaves the health unknown.
+func smartctlHealth(ctx context.Context, name string) (*helper.DiskHealth, error) {
+	output, err := runCommand(ctx, "smartctl", "--json", "-a", "/dev/"+name)
+	// The exit status is a bit mask: bits 0 and 1 mean no data was read,
+	// higher bits warn about the disk and come with the full report
+	var exitErr *helper.ExitError
+	switch {
+	case errors.As(err, &exitErr) && exitErr.Code&0x3 != 0:
+		return nil, nil
+	case err != nil && !errors.As(err, &exitErr):
+		if errors.Is(err, helper.ErrToolMissing) || errors.Is(err, helper.ErrOtherRoot) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return parseSmartctl(output)
+}
+
+// Helper function to parse the output of `smartctl --json -a`
+func parseSmartctl(output []byte) (*helper.DiskHealth, error) {
+	var report smartctlOutput
+	if err := json.Unmarshal(output, &report); err != nil {
+		return nil, fmt.Errorf("smartctl: %w", err)
+	}
+
+	health := &helper.DiskHealth{Source: "smartctl"}
+	if report.ATAAttributes != nil {
+		raw := make(map[byte]uint64)
+		for _, attribute := range report.ATAAttributes.Table {
+			raw[attribute.ID] = attribute.Raw.Value
+		}
+		health = ataHealth("smartctl", raw)
+	}
+	if report.NVMeLog != nil {
+		health.PercentageUsed = ptr(report.NVMeLog.PercentageUsed)
+		health.MediaErrors = ptr(report.NVMeLog.MediaErrors)
+	}
+	if report.Temperature != nil {
+		health.Temperature = ptr(report.Temperature.Current)
+	}
+	if report.PowerOnTime != nil {
+		health.PowerOnHours = ptr(report.PowerOnTime.Hours)
+	}
+	if report.PowerCycleCount != nil {
+		health.PowerCycles = report.PowerCycleCount
+	}
+	return health, nil
+}
+
+// Helper function to get a pointer to a value, for fields that are nil when
+// the value is unknown
+func ptr[T any](value T) *T {
+	return &value
+}
@@ -0,0 +1,107 @@
+package linux
+
+import (
+	"defetch/helper"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func readSmart(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "smart", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestParseNVMeSmartLog(t *testing.T) {
+	// The log page returned by the NVMe admin command
+	got, err := parseNVMeSmartLog(readSmart(t, "nvme-smart-log-laptop.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &helper.DiskHealth{
+		Source:         "nvme",
+		Temperature:    ptr(38.0),
+		PowerOnHours:   ptr(uint64(4210)),
+		PowerCycles:    ptr(uint64(1532)),
+		MediaErrors:    ptr(uint64(0)),
+		PercentageUsed: ptr(3),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	if _, err := parseNVMeSmartLog(make([]byte, 64)); err == nil {
+		t.Error("no error for a short log")
+	}
+}
+
+func TestParseATASmartData(t *testing.T) {
+	// The data returned by SMART READ DATA over SG_IO
+	data := readSmart(t, "ata-smart-data-sata.bin")
+	got, err := parseATASmartData(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Only the lowest bytes of the hours and temperature are read
+	want := &helper.DiskHealth{
+		Source:             "ata",
+		Temperature:        ptr(36.0),
+		PowerOnHours:       ptr(uint64(10734)),
+		PowerCycles:        ptr(uint64(412)),
+		MediaErrors:        ptr(uint64(2)),
+		ReallocatedSectors: ptr(uint64(8)),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	data[100]++
+	if _, err := parseATASmartData(data); err == nil {
+		t.Error("no error for a wrong checksum")
+	}
+}
+
+func TestParseSmartctl(t *testing.T) {
+	output := []byte(`{
+		"temperature": {"current": 31},
+		"power_on_time": {"hours": 20311},
+		"power_cycle_count": 77,
+		"ata_smart_attributes": {"table": [
+			{"id": 5, "name": "Reallocated_Sector_Ct", "raw": {"value": 0, "string": "0"}},
+			{"id": 9, "name": "Power_On_Hours", "raw": {"value": 20311, "string": "20311"}},
+			{"id": 194, "name": "Temperature_Celsius", "raw": {"value": 133144248351, "string": "31 (Min/Max 17/31)"}}
+		]}
+	}`)
+	got, err := parseSmartctl(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &helper.DiskHealth{
+		Source:             "smartctl",
+		Temperature:        ptr(31.0),
+		PowerOnHours:       ptr(uint64(20311)),
+		PowerCycles:        ptr(uint64(77)),
+		ReallocatedSectors: ptr(uint64(0)),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestAddDiskHealth(t *testing.T) {
+	nvme := &helper.DiskHealth{Source: "nvme"}
+	sata := &helper.DiskHealth{Source: "ata"}
+	sysInfo := helper.SysInfo{Storage: []helper.StorageInfo{{Device: "nvme0n1"}, {Device: "zram0"}}}
+	addDiskHealth(&sysInfo, diskHealth{"nvme0n1": nvme, "sdb": sata})
+
+	// sdb was not reported by the storage collector
+	want := []helper.StorageInfo{{Device: "nvme0n1", Health: nvme}, {Device: "zram0"}, {Device: "sdb", Health: sata}}
+	if !reflect.DeepEqual(sysInfo.Storage, want) {
+		t.Errorf("got %+v, want %+v", sysInfo.Storage, want)
+	}
+}
@@ -0,0 +1,128 @@
+package linux
+
+import (
+	"defetch/helper"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// meminfo holds the fields of /proc/meminfo. Fields in kB are converted to
+// bytes, unitless fields such as HugePages_Total are kept as counts.
+type meminfo map[string]helper.Bytes
+
+// Helper function to read /proc/meminfo
+func readMeminfo(fsys fs.FS) (meminfo, error) {
+	content, err := fs.ReadFile(fsys, "proc/meminfo")
+	if err != nil {
+		return nil, err
+	}
+	return parseMeminfo(string(content)), nil
+}
+
+// Helper function to parse the "Key:   value kB" lines of /proc/meminfo
+func parseMeminfo(content string) meminfo {
+	info := make(meminfo)
+	for _, line := range strings.Split(content, "\n") {
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		info[strings.TrimSpace(key)] = parseSize(value)
+	}
+	return info
+}
+
+// Helper function to get the memory that is in use. Older kernels without
+// MemAvailable count buffers and caches as free, like free(1) does.
+func (m meminfo) used() helper.Bytes {
+	available, ok := m["MemAvailable"]
+	if !ok {
+		available = m["MemFree"] + m["Buffers"] + m["Cached"] + m["SReclaimable"]
+	}
+	if available > m["MemTotal"] {
+		return 0
+	}
+	return m["MemTotal"] - available
+}
+
+// Helper function to fill the MemoryInfo fields that come from /proc/meminfo
+func (m meminfo) memoryInfo() helper.MemoryInfo {
+	swapUsed := helper.Bytes(0)
+	if m["SwapTotal"] > m["SwapFree"] {
+		swapUsed = m["SwapTotal"] - m["SwapFree"]
+	}
+
+	return helper.MemoryInfo{
+		TotalSize:    m["MemTotal"],
+		UsedSize:     m.used(),
+		FreeSize:     m["MemFree"],
+		Available:    m["MemAvailable"],
+		Buffers:      m["Buffers"],
+		Cached:       m["Cached"],
+		Shared:       m["Shmem"],
+		SReclaimable: m["SReclaimable"],
+		Dirty:        m["Dirty"],
+		Swap: helper.SwapInfo{
+			Total:  m["SwapTotal"],
+			Used:   swapUsed,
+			Free:   m["SwapFree"],
+			Cached: m["SwapCached"],
+		},
+		HugePages: helper.HugePagesInfo{
+			Total:    int(m["HugePages_Total"]),
+			Free:     int(m["HugePages_Free"]),
+			Reserved: int(m["HugePages_Rsvd"]),
+			Surplus:  int(m["HugePages_Surp"]),
+			PageSize: m["Hugepagesize"],
+			Size:     m["Hugetlb"],
+		},
+	}
+}
+
+// Helper function to get the zram devices and their compression statistics
+func getZramDevices(fsys fs.FS) []helper.ZramDeviceInfo {
+	dirs, _ := fs.Glob(fsys, "sys/block/zram*")
+
+	var devices []helper.ZramDeviceInfo
+	for _, dir := range dirs {
+		device := helper.ZramDeviceInfo{
+			Device:    path.Base(dir),
+			Algorithm: selectedAlgorithm(readSysfs(fsys, path.Join(dir, "comp_algorithm"))),
+			DiskSize:  parseBytes(readSysfs(fsys, path.Join(dir, "disksize"))),
+		}
+
+		// mm_stat starts with orig_data_size, compr_data_size and mem_used_total
+		stats := strings.Fields(readSysfs(fsys, path.Join(dir, "mm_stat")))
+		if len(stats) >= 3 {
+			device.OriginalSize = parseBytes(stats[0])
+			device.CompressedSize = parseBytes(stats[1])
+			device.MemoryUsed = parseBytes(stats[2])
+		}
+		if device.CompressedSize > 0 {
+			device.CompressionRatio = float64(device.OriginalSize) / float64(device.CompressedSize)
+		}
+
+		devices = append(devices, device)
+	}
+	return devices
+}
+
+// Helper function to pick the algorithm in brackets from a list like "lzo [lz4] zstd"
+func selectedAlgorithm(list string) string {
+	for _, algorithm := range strings.Fields(list) {
+		if strings.HasPrefix(algorithm, "[") {
+			return strings.Trim(algorithm, "[]")
+		}
+	}
+	return list
+}
+
+// Helper function to read a sysfs attribute, returning "" if it is missing
+func readSysfs(fsys fs.FS, name string) string {
+	content, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(content))
+}
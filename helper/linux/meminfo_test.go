@@ -0,0 +1,61 @@
+package linux
+
+import (
+	"defetch/helper"
+	"reflect"
+	"testing"
+)
+
+func TestMeminfo(t *testing.T) {
+	meminfo, err := readMeminfo(fixture("laptop"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const kB = 1024
+	got := meminfo.memoryInfo()
+	want := helper.MemoryInfo{
+		TotalSize:    16000000 * kB,
+		UsedSize:     8000000 * kB,
+		FreeSize:     2000000 * kB,
+		Available:    8000000 * kB,
+		Buffers:      500000 * kB,
+		Cached:       5000000 * kB,
+		Shared:       300000 * kB,
+		SReclaimable: 400000 * kB,
+		Dirty:        1200 * kB,
+		Swap:         helper.SwapInfo{Total: 4000000 * kB, Used: 1000000 * kB, Free: 3000000 * kB, Cached: 1000 * kB},
+		HugePages:    helper.HugePagesInfo{PageSize: 2048 * kB},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestMeminfoWithoutMemAvailable(t *testing.T) {
+	// Kernels before 3.14 count buffers, caches and reclaimable slab as free
+	meminfo := parseMeminfo("MemTotal: 1000 kB\nMemFree: 100 kB\nBuffers: 50 kB\nCached: 200 kB\nSReclaimable: 50 kB\n")
+	if got, want := meminfo.used(), helper.Bytes(600*1024); got != want {
+		t.Errorf("used() = %v, want %v", got, want)
+	}
+}
+
+func TestZramDevices(t *testing.T) {
+	got := getZramDevices(fixture("laptop"))
+	want := []helper.ZramDeviceInfo{{
+		Device:           "zram0",
+		Algorithm:        "zstd",
+		DiskSize:         8 << 30,
+		OriginalSize:     1 << 30,
+		CompressedSize:   256 << 20,
+		MemoryUsed:       272 << 20,
+		CompressionRatio: 4,
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	if got := getZramDevices(fixture("convertible")); got != nil {
+		t.Errorf("got %+v without zram", got)
+	}
+}
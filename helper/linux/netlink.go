@@ -0,0 +1,145 @@
+package linux
+
+import (
+	"encoding/binary"
+	"errors"
+	"syscall"
+)
+
+// Generic netlink controller from include/uapi/linux/genetlink.h, which
+// resolves the names of families like nl80211 to their message type
+const (
+	genlIDCtrl         = 0x10
+	ctrlCmdGetFamily   = 3
+	ctrlAttrFamilyID   = 1
+	ctrlAttrFamilyName = 2
+
+	sizeofGenlMsghdr = 4
+)
+
+// Helper function to split the attributes following the fixed header of a
+// netlink message by their type. syscall.ParseNetlinkRouteAttr only knows the
+// headers of links, addresses and routes.
+func parseAttributes(data []byte) map[uint16][]byte {
+	attrs := make(map[uint16][]byte)
+	for len(data) >= syscall.SizeofRtAttr {
+		length := int(binary.NativeEndian.Uint16(data[0:2]))
+		if length < syscall.SizeofRtAttr || length > len(data) {
+			break
+		}
+		// The top bits flag nested and byte-order attributes
+		kind := binary.NativeEndian.Uint16(data[2:4]) & 0x3fff
+		attrs[kind] = data[syscall.SizeofRtAttr:length]
+
+		aligned := (length + syscall.RTA_ALIGNTO - 1) &^ (syscall.RTA_ALIGNTO - 1)
+		if aligned >= len(data) {
+			break
+		}
+		data = data[aligned:]
+	}
+	return attrs
+}
+
+// Helper function to encode a netlink attribute, padded to its alignment
+func netlinkAttribute(kind uint16, value []byte) []byte {
+	length := syscall.SizeofRtAttr + len(value)
+	attr := make([]byte, (length+syscall.RTA_ALIGNTO-1)&^(syscall.RTA_ALIGNTO-1))
+	binary.NativeEndian.PutUint16(attr[0:2], uint16(length))
+	binary.NativeEndian.PutUint16(attr[2:4], kind)
+	copy(attr[syscall.SizeofRtAttr:], value)
+	return attr
+}
+
+// Helper function to send a generic netlink request and return the raw
+// replies, up to the end of a dump or the acknowledgement
+func genetlinkRequest(family uint16, command uint8, flags uint16, attrs []byte) ([]byte, error) {
+	fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_RAW|syscall.SOCK_CLOEXEC, syscall.NETLINK_GENERIC)
+	if err != nil {
+		return nil, err
+	}
+	defer syscall.Close(fd)
+	kernel := &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK}
+	if err := syscall.Bind(fd, &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK}); err != nil {
+		return nil, err
+	}
+
+	// struct nlmsghdr and struct genlmsghdr: command, version, reserved
+	request := make([]byte, syscall.NLMSG_HDRLEN+sizeofGenlMsghdr, syscall.NLMSG_HDRLEN+sizeofGenlMsghdr+len(attrs))
+	request = append(request, attrs...)
+	binary.NativeEndian.PutUint32(request[0:4], uint32(len(request)))
+	binary.NativeEndian.PutUint16(request[4:6], family)
+	binary.NativeEndian.PutUint16(request[6:8], syscall.NLM_F_REQUEST|syscall.NLM_F_ACK|flags)
+	binary.NativeEndian.PutUint32(request[8:12], 1)
+	request[syscall.NLMSG_HDRLEN] = command
+	request[syscall.NLMSG_HDRLEN+1] = 1
+	if err := syscall.Sendto(fd, request, 0, kernel); err != nil {
+		return nil, err
+	}
+
+	var replies []byte
+	buf := make([]byte, 64*1024)
+	for {
+		n, _, err := syscall.Recvfrom(fd, buf, 0)
+		if err != nil {
+			return nil, err
+		}
+		messages, err := syscall.ParseNetlinkMessage(buf[:n])
+		if err != nil {
+			return nil, err
+		}
+		replies = append(replies, buf[:n]...)
+
+		for _, message := range messages {
+			switch message.Header.Type {
+			case syscall.NLMSG_DONE:
+				return replies, nil
+			case syscall.NLMSG_ERROR:
+				// An error code of 0 acknowledges a request that is not a dump
+				if len(message.Data) < 4 {
+					return nil, errors.New("truncated netlink error")
+				}
+				if code := int32(binary.NativeEndian.Uint32(message.Data[0:4])); code != 0 {
+					return nil, syscall.Errno(-code)
+				}
+				return replies, nil
+			}
+		}
+	}
+}
+
+// Helper function to get the attributes of the messages in the replies to a
+// generic netlink request
+func genetlinkAttributes(data []byte) ([]map[uint16][]byte, error) {
+	messages, err := syscall.ParseNetlinkMessage(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var replies []map[uint16][]byte
+	for _, message := range messages {
+		// Control messages use the types below NLMSG_MIN_TYPE
+		if message.Header.Type < syscall.NLMSG_MIN_TYPE || len(message.Data) < sizeofGenlMsghdr {
+			continue
+		}
+		replies = append(replies, parseAttributes(message.Data[sizeofGenlMsghdr:]))
+	}
+	return replies, nil
+}
+
+// Helper function to resolve the message type of a generic netlink family
+func genetlinkFamily(name string) (uint16, error) {
+	data, err := genetlinkRequest(genlIDCtrl, ctrlCmdGetFamily, 0, netlinkAttribute(ctrlAttrFamilyName, append([]byte(name), 0)))
+	if err != nil {
+		return 0, err
+	}
+	replies, err := genetlinkAttributes(data)
+	if err != nil {
+		return 0, err
+	}
+	for _, attrs := range replies {
+		if id := attrs[ctrlAttrFamilyID]; len(id) == 2 {
+			return binary.NativeEndian.Uint16(id), nil
+		}
+	}
+	return 0, errors.New("no family " + name)
+}
@@ -0,0 +1,266 @@
+package linux
+
+import (
+	"defetch/helper"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// netDir lists the network interfaces
+const netDir = "sys/class/net"
+
+// Interface types from include/uapi/linux/if_arp.h that are not ethernet-like
+const (
+	arphrdLoopback = 772
+	arphrdNone     = 65534 // Used by tun devices
+)
+
+// Helper function to get one entry per network interface from sysfs, with the
+// addresses the kernel reports over netlink
+func getNetworkInfo(fsys fs.FS) ([]helper.NetworkInfo, error) {
+	entries, err := fs.ReadDir(fsys, netDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var networks []helper.NetworkInfo
+	for _, entry := range entries {
+		networks = append(networks, getInterface(fsys, entry.Name()))
+	}
+	sort.Slice(networks, func(i, j int) bool {
+		return networks[i].Index < networks[j].Index
+	})
+
+	gateways := readDefaultGateways(fsys)
+	for i := range networks {
+		networks[i].DefaultGateway = gateways[networks[i].InterfaceName]
+		if networks[i].Type == "wifi" {
+			networks[i].Wireless = getWirelessInfo(fsys, networks[i].Index, networks[i].InterfaceName)
+		}
+	}
+
+	// Addresses are not in sysfs and netlink only knows the running system
+	if !helper.IsLive(fsys) {
+		return networks, nil
+	}
+	addresses, err := getAddresses()
+	if err != nil {
+		return networks, fmt.Errorf("netlink: %w", err)
+	}
+	for i := range networks {
+		networks[i].Addresses = addresses[networks[i].Index]
+	}
+
+	return networks, nil
+}
+
+// Helper function to read the attributes of one interface
+func getInterface(fsys fs.FS, name string) helper.NetworkInfo {
+	dir := path.Join(netDir, name)
+	attr := func(name string) string {
+		return readSysfs(fsys, path.Join(dir, name))
+	}
+	counter := func(name string) uint64 {
+		value, _ := strconv.ParseUint(attr(path.Join("statistics", name)), 10, 64)
+		return value
+	}
+
+	network := helper.NetworkInfo{
+		InterfaceName: name,
+		Index:         atoi(attr("ifindex")),
+		MACAddress:    attr("address"),
+		MTU:           atoi(attr("mtu")),
+		OperState:     attr("operstate"),
+		// Reading carrier, speed and duplex fails while the interface is down
+		Carrier: attr("carrier") == "1",
+		Duplex:  attr("duplex"),
+		Statistics: helper.InterfaceStats{
+			RXBytes:   helper.Bytes(counter("rx_bytes")),
+			TXBytes:   helper.Bytes(counter("tx_bytes")),
+			RXPackets: counter("rx_packets"),
+			TXPackets: counter("tx_packets"),
+			RXErrors:  counter("rx_errors"),
+			TXErrors:  counter("tx_errors"),
+			RXDropped: counter("rx_dropped"),
+			TXDropped: counter("tx_dropped"),
+		},
+	}
+	// Virtual interfaces report -1
+	if speed := atoi(attr("speed")); speed > 0 {
+		network.SpeedMbps = speed
+	}
+	// Loopback and tun devices have no link state of their own and stay unknown
+	network.Active = network.OperState == "up" || network.OperState == "unknown" && network.Carrier
+
+	if driver, err := helper.ReadLink(fsys, path.Join(dir, "device", "driver")); err == nil {
+		network.Driver = path.Base(driver)
+	}
+	network.Type = interfaceType(fsys, dir)
+
+	return network
+}
+
+// Helper function to classify an interface by the traces its kind leaves in sysfs
+func interfaceType(fsys fs.FS, dir string) string {
+	exists := func(name string) bool {
+		_, err := fs.Stat(fsys, path.Join(dir, name))
+		return err == nil
+	}
+
+	arpType := atoi(readSysfs(fsys, path.Join(dir, "type")))
+	if arpType == arphrdLoopback {
+		return "loopback"
+	}
+	// cfg80211 links its radio, wireless extensions add their own directory
+	if exists("phy80211") || exists("wireless") {
+		return "wifi"
+	}
+
+	// Bridges, bonds, VLANs and WireGuard set DEVTYPE
+	for _, line := range strings.Split(readSysfs(fsys, path.Join(dir, "uevent")), "\n") {
+		if devType, found := strings.CutPrefix(line, "DEVTYPE="); found {
+			if devType == "wlan" {
+				return "wifi"
+			}
+			return devType
+		}
+	}
+
+	switch {
+	case exists("bridge"):
+		return "bridge"
+	case exists("bonding"):
+		return "bond"
+	case exists("tun_flags"):
+		return "tun"
+	case arpType == arphrdNone:
+		return "none"
+	case exists("device"):
+		return "ethernet"
+	}
+
+	// A virtual ethernet device linked to another interface is one end of a veth pair
+	if readSysfs(fsys, path.Join(dir, "iflink")) != readSysfs(fsys, path.Join(dir, "ifindex")) {
+		return "veth"
+	}
+	return "virtual"
+}
+
+// Helper function to get the gateway of the preferred IPv4 default route of
+// each interface
+func readDefaultGateways(fsys fs.FS) map[string]string {
+	routes, _ := readIPv4Routes(fsys)
+
+	gateways := make(map[string]string)
+	for _, gateway := range defaultGateways(routes) {
+		if _, ok := gateways[gateway.Interface]; !ok {
+			gateways[gateway.Interface] = gateway.Gateway
+		}
+	}
+	return gateways
+}
+
+// Helper function to parse an IPv4 address as /proc/net/route prints it, in
+// hexadecimal and host byte order
+func parseHexIPv4(value string) net.IP {
+	n, err := strconv.ParseUint(value, 16, 32)
+	if err != nil {
+		return nil
+	}
+	ip := make(net.IP, net.IPv4len)
+	binary.NativeEndian.PutUint32(ip, uint32(n))
+	return ip
+}
+
+// Helper function to get the addresses of all interfaces by their index
+func getAddresses() (map[int][]helper.AddressInfo, error) {
+	data, err := syscall.NetlinkRIB(syscall.RTM_GETADDR, syscall.AF_UNSPEC)
+	if err != nil {
+		return nil, err
+	}
+	return parseAddresses(data)
+}
+
+// Helper function to parse the RTM_NEWADDR messages of a netlink address dump
+func parseAddresses(data []byte) (map[int][]helper.AddressInfo, error) {
+	messages, err := syscall.ParseNetlinkMessage(data)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := make(map[int][]helper.AddressInfo)
+	for _, message := range messages {
+		switch message.Header.Type {
+		case syscall.NLMSG_DONE:
+			return addresses, nil
+		case syscall.NLMSG_ERROR:
+			return nil, errors.New("address dump failed")
+		case syscall.RTM_NEWADDR:
+		default:
+			continue
+		}
+		if len(message.Data) < syscall.SizeofIfAddrmsg {
+			continue
+		}
+
+		// struct ifaddrmsg: family, prefixlen, flags, scope, index
+		header := message.Data[:syscall.SizeofIfAddrmsg]
+		attrs, err := syscall.ParseNetlinkRouteAttr(&message)
+		if err != nil {
+			return nil, err
+		}
+
+		// IFA_LOCAL is the address itself on point-to-point links, where
+		// IFA_ADDRESS is the peer
+		var ip net.IP
+		for _, attr := range attrs {
+			switch attr.Attr.Type {
+			case syscall.IFA_LOCAL:
+				ip = net.IP(attr.Value)
+			case syscall.IFA_ADDRESS:
+				if ip == nil {
+					ip = net.IP(attr.Value)
+				}
+			}
+		}
+		if ip == nil {
+			continue
+		}
+
+		family := "ipv6"
+		if header[0] == syscall.AF_INET {
+			family = "ipv4"
+		}
+		index := int(binary.NativeEndian.Uint32(header[4:8]))
+		addresses[index] = append(addresses[index], helper.AddressInfo{
+			Address:      ip.String(),
+			PrefixLength: int(header[1]),
+			Family:       family,
+			Scope:        addressScope(header[3]),
+		})
+	}
+	return addresses, nil
+}
+
+// Helper function to name an address scope from include/uapi/linux/rtnetlink.h
+func addressScope(scope uint8) string {
+	switch scope {
+	case syscall.RT_SCOPE_UNIVERSE:
+		return "global"
+	case syscall.RT_SCOPE_SITE:
+		return "site"
+	case syscall.RT_SCOPE_LINK:
+		return "link"
+	case syscall.RT_SCOPE_HOST:
+		return "host"
+	}
+	return strconv.Itoa(int(scope))
+}
@@ -0,0 +1,113 @@
+package linux
+
+import (
+	"defetch/helper"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// Helper function to read a recorded netlink reply
+func readNetlink(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "netlink", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestNetworkInfo(t *testing.T) {
+	networks, err := getNetworkInfo(fixture("laptop"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type summary struct {
+		Name, Type, Driver, OperState string
+		Active                        bool
+		SpeedMbps                     int
+		Gateway                       string
+	}
+	var got []summary
+	for _, network := range networks {
+		got = append(got, summary{network.InterfaceName, network.Type, network.Driver, network.OperState, network.Active, network.SpeedMbps, network.DefaultGateway})
+	}
+	// Sorted by index, and the default route of tun0 has no gateway
+	want := []summary{
+		{"lo", "loopback", "", "unknown", true, 0, ""},
+		{"enp0s31f6", "ethernet", "e1000e", "down", false, 0, ""},
+		{"wlp0s20f3", "wifi", "iwlwifi", "up", true, 0, "192.168.1.1"},
+		{"docker0", "bridge", "", "up", true, 0, ""},
+		{"veth1a2b3c4", "veth", "", "up", true, 10000, ""},
+		{"tun0", "tun", "", "unknown", true, 10, ""},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	wifi := networks[2]
+	if wifi.MACAddress != "3c:9c:0f:12:34:56" || wifi.MTU != 1500 || !wifi.Carrier {
+		t.Errorf("wifi: got MAC %q, MTU %d, carrier %v", wifi.MACAddress, wifi.MTU, wifi.Carrier)
+	}
+	wantStats := helper.InterfaceStats{
+		RXBytes:   1073741824,
+		TXBytes:   52428800,
+		RXPackets: 812345,
+		TXPackets: 223344,
+		RXErrors:  3,
+		RXDropped: 12,
+	}
+	if wifi.Statistics != wantStats {
+		t.Errorf("wifi: got statistics %+v, want %+v", wifi.Statistics, wantStats)
+	}
+	if wifi.Wireless == nil || wifi.Wireless.SignalDBm != -52 {
+		t.Errorf("wifi: got wireless %+v", wifi.Wireless)
+	}
+
+	// Netlink describes the running system, so another root has no addresses
+	for _, network := range networks {
+		if network.Type != "wifi" && network.Wireless != nil {
+			t.Errorf("%s: got wireless %+v", network.InterfaceName, network.Wireless)
+		}
+		if network.Addresses != nil {
+			t.Errorf("%s: got addresses %+v below another root", network.InterfaceName, network.Addresses)
+		}
+	}
+}
+
+func TestParseAddresses(t *testing.T) {
+	// An RTM_GETADDR dump in little-endian byte order
+	got, err := parseAddresses(readNetlink(t, "getaddr-laptop.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[int][]helper.AddressInfo{
+		1: {
+			{Address: "127.0.0.1", PrefixLength: 8, Family: "ipv4", Scope: "host"},
+			{Address: "::1", PrefixLength: 128, Family: "ipv6", Scope: "host"},
+		},
+		3: {
+			{Address: "192.168.1.23", PrefixLength: 24, Family: "ipv4", Scope: "global"},
+			{Address: "2001:db8::23", PrefixLength: 64, Family: "ipv6", Scope: "global"},
+			{Address: "fe80::3e9c:fff:fe12:3456", PrefixLength: 64, Family: "ipv6", Scope: "link"},
+		},
+		4: {{Address: "172.17.0.1", PrefixLength: 16, Family: "ipv4", Scope: "global"}},
+		// The local end of the point-to-point link, not the peer
+		7: {{Address: "10.8.0.2", PrefixLength: 32, Family: "ipv4", Scope: "global"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestParseHexIPv4(t *testing.T) {
+	if got := parseHexIPv4("0101A8C0"); got.String() != "192.168.1.1" {
+		t.Errorf("got %v, want 192.168.1.1", got)
+	}
+	if got := parseHexIPv4("not hex"); got != nil {
+		t.Errorf("got %v for an invalid address", got)
+	}
+}
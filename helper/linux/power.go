@@ -0,0 +1,137 @@
+package linux
+
+import (
+	"defetch/helper"
+	"errors"
+	"io/fs"
+	"math"
+	"path"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// powerSupplyDir lists the batteries and adapters known to the kernel
+const powerSupplyDir = "sys/class/power_supply"
+
+// Helper function to get the adapters and batteries. Machines without any
+// power supply report none.
+func getPowerInfo(fsys fs.FS) (helper.PowerInfo, error) {
+	entries, err := fs.ReadDir(fsys, powerSupplyDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return helper.PowerInfo{}, nil
+	}
+	if err != nil {
+		return helper.PowerInfo{}, err
+	}
+
+	var power helper.PowerInfo
+	for _, entry := range entries {
+		dir := path.Join(powerSupplyDir, entry.Name())
+		switch supplyType := readSysfs(fsys, path.Join(dir, "type")); supplyType {
+		case "Battery", "UPS":
+			// Empty battery bays still have an entry
+			if readSysfs(fsys, path.Join(dir, "present")) == "0" {
+				continue
+			}
+			power.Batteries = append(power.Batteries, getBattery(fsys, entry.Name()))
+		case "":
+			continue
+		default:
+			adapter := helper.AdapterInfo{
+				Name:   entry.Name(),
+				Type:   supplyType,
+				Online: readSysfs(fsys, path.Join(dir, "online")) == "1",
+			}
+			power.OnAC = power.OnAC || adapter.Online
+			power.Adapters = append(power.Adapters, adapter)
+		}
+	}
+
+	sort.Slice(power.Batteries, func(i, j int) bool {
+		return power.Batteries[i].Name < power.Batteries[j].Name
+	})
+	return power, nil
+}
+
+// Helper function to read one battery. Drivers report either energy_* in µWh
+// or charge_* in µAh, which is converted to energy using the design voltage.
+func getBattery(fsys fs.FS, name string) helper.BatteryInfo {
+	dir := path.Join(powerSupplyDir, name)
+	text := func(name string) string {
+		return readSysfs(fsys, path.Join(dir, name))
+	}
+	attr := func(name string) (float64, bool) {
+		value, err := strconv.ParseFloat(text(name), 64)
+		return value, err == nil
+	}
+
+	battery := helper.BatteryInfo{
+		Name:         name,
+		Scope:        text("scope"),
+		Status:       text("status"),
+		Manufacturer: text("manufacturer"),
+		Model:        text("model_name"),
+		Serial:       text("serial_number"),
+		Technology:   text("technology"),
+		CycleCount:   atoi(text("cycle_count")),
+	}
+
+	voltageNow, _ := attr("voltage_now")
+	battery.Voltage = voltageNow / 1e6
+
+	if _, ok := attr("energy_full"); ok {
+		battery.EnergyNow, _ = attr("energy_now")
+		battery.EnergyFull, _ = attr("energy_full")
+		battery.EnergyFullDesign, _ = attr("energy_full_design")
+		battery.EnergyNow /= 1e6
+		battery.EnergyFull /= 1e6
+		battery.EnergyFullDesign /= 1e6
+	} else if _, ok := attr("charge_full"); ok {
+		voltage, ok := attr("voltage_min_design")
+		if !ok {
+			voltage = voltageNow
+		}
+		chargeNow, _ := attr("charge_now")
+		chargeFull, _ := attr("charge_full")
+		chargeFullDesign, _ := attr("charge_full_design")
+		battery.EnergyNow = chargeNow * voltage / 1e12
+		battery.EnergyFull = chargeFull * voltage / 1e12
+		battery.EnergyFullDesign = chargeFullDesign * voltage / 1e12
+	}
+
+	if capacity, ok := attr("capacity"); ok {
+		battery.Percentage = capacity
+	} else if battery.EnergyFull > 0 {
+		battery.Percentage = math.Min(battery.EnergyNow/battery.EnergyFull*100, 100)
+	}
+	if battery.EnergyFull > 0 && battery.EnergyFullDesign > 0 {
+		battery.Wear = math.Max(0, 100-battery.EnergyFull/battery.EnergyFullDesign*100)
+	}
+
+	// power_now is in µW; drivers without it report the current in µA
+	if powerNow, ok := attr("power_now"); ok {
+		battery.PowerDraw = math.Abs(powerNow) / 1e6
+	} else if currentNow, ok := attr("current_now"); ok {
+		battery.PowerDraw = math.Abs(currentNow) * voltageNow / 1e12
+	}
+
+	// Prefer the estimates of the driver over our own
+	if seconds, ok := attr("time_to_empty_now"); ok {
+		battery.TimeToEmpty = time.Duration(seconds) * time.Second
+	} else if battery.Status == "Discharging" && battery.PowerDraw > 0 {
+		battery.TimeToEmpty = hours(battery.EnergyNow / battery.PowerDraw)
+	}
+	if seconds, ok := attr("time_to_full_now"); ok {
+		battery.TimeToFull = time.Duration(seconds) * time.Second
+	} else if battery.Status == "Charging" && battery.PowerDraw > 0 {
+		battery.TimeToFull = hours(math.Max(0, battery.EnergyFull-battery.EnergyNow) / battery.PowerDraw)
+	}
+
+	return battery
+}
+
+// Helper function to convert fractional hours to a duration, rounded to seconds
+func hours(h float64) time.Duration {
+	return (time.Duration(h * float64(time.Hour))).Round(time.Second)
+}
@@ -0,0 +1,134 @@
+package linux
+
+import (
+	"defetch/helper"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPowerInfoEnergyBattery(t *testing.T) {
+	power, err := getPowerInfo(fixture("laptop"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if power.OnAC {
+		t.Error("OnAC = true with the adapter offline")
+	}
+	wantAdapters := []helper.AdapterInfo{{Name: "AC", Type: "Mains", Online: false}}
+	if !reflect.DeepEqual(power.Adapters, wantAdapters) {
+		t.Errorf("Adapters = %+v, want %+v", power.Adapters, wantAdapters)
+	}
+	if len(power.Batteries) != 2 {
+		t.Fatalf("got %d batteries, want 2", len(power.Batteries))
+	}
+
+	battery := power.Batteries[0]
+	want := helper.BatteryInfo{
+		Name:             "BAT0",
+		Status:           "Discharging",
+		Percentage:       80,
+		Manufacturer:     "SMP",
+		Model:            "5B10W13930",
+		Serial:           "1234",
+		Technology:       "Li-poly",
+		CycleCount:       123,
+		EnergyNow:        40,
+		EnergyFull:       50,
+		EnergyFullDesign: 57,
+		Wear:             100 - 50.0/57*100,
+		Voltage:          11.8,
+		PowerDraw:        10,
+		TimeToEmpty:      4 * time.Hour,
+	}
+	assertBattery(t, battery, want)
+
+	device := power.Batteries[1]
+	if device.Name != "hidpp_battery_0" || device.Scope != "Device" || device.Percentage != 55 || device.Model != "MX Master 3" {
+		t.Errorf("peripheral battery = %+v", device)
+	}
+}
+
+func TestPowerInfoChargeBattery(t *testing.T) {
+	power, err := getPowerInfo(fixture("convertible"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !power.OnAC {
+		t.Error("OnAC = false with the adapter online")
+	}
+	// BAT1 is an empty bay and left out
+	if len(power.Batteries) != 2 {
+		t.Fatalf("got %d batteries, want 2", len(power.Batteries))
+	}
+
+	// charge_* in µAh are converted with voltage_min_design, current_now gives the power
+	assertBattery(t, power.Batteries[0], helper.BatteryInfo{
+		Name:             "BAT0",
+		Status:           "Charging",
+		Percentage:       50,
+		Technology:       "Li-ion",
+		EnergyNow:        20,
+		EnergyFull:       40,
+		EnergyFullDesign: 50,
+		Wear:             20,
+		Voltage:          12,
+		PowerDraw:        12,
+		TimeToFull:       time.Hour + 40*time.Minute,
+	})
+
+	// The estimate of the driver wins over the computed one
+	assertBattery(t, power.Batteries[1], helper.BatteryInfo{
+		Name:       "BAT2",
+		Status:     "Charging",
+		Percentage: 90,
+		EnergyNow:  45,
+		EnergyFull: 50,
+		PowerDraw:  5,
+		TimeToFull: 30 * time.Minute,
+	})
+}
+
+func TestPowerInfoWithoutPowerSupplies(t *testing.T) {
+	power, err := getPowerInfo(fixture("missing"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(power, helper.PowerInfo{}) {
+		t.Errorf("got %+v for a machine without power supplies", power)
+	}
+}
+
+// assertBattery compares batteries, allowing for rounding in the unit conversions
+func assertBattery(t *testing.T, got, want helper.BatteryInfo) {
+	t.Helper()
+
+	floats := []struct {
+		name      string
+		got, want float64
+	}{
+		{"Percentage", got.Percentage, want.Percentage},
+		{"EnergyNow", got.EnergyNow, want.EnergyNow},
+		{"EnergyFull", got.EnergyFull, want.EnergyFull},
+		{"EnergyFullDesign", got.EnergyFullDesign, want.EnergyFullDesign},
+		{"Wear", got.Wear, want.Wear},
+		{"Voltage", got.Voltage, want.Voltage},
+		{"PowerDraw", got.PowerDraw, want.PowerDraw},
+	}
+	for _, f := range floats {
+		if math.Abs(f.got-f.want) > 1e-9 {
+			t.Errorf("%s: %s = %v, want %v", want.Name, f.name, f.got, f.want)
+		}
+	}
+
+	got.Percentage, got.EnergyNow, got.EnergyFull, got.EnergyFullDesign = 0, 0, 0, 0
+	got.Wear, got.Voltage, got.PowerDraw = 0, 0, 0
+	want.Percentage, want.EnergyNow, want.EnergyFull, want.EnergyFullDesign = 0, 0, 0, 0
+	want.Wear, want.Voltage, want.PowerDraw = 0, 0, 0
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("%s = %+v, want %+v", want.Name, got, want)
+	}
+}
@@ -0,0 +1,144 @@
+package linux
+
+import (
+	"context"
+	"defetch/helper"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultSampleInterval is the time between the two /proc/stat samples CPU
+// usage is computed from, unless the performance collector sets "interval"
+const defaultSampleInterval = 250 * time.Millisecond
+
+// cpuTicks holds the cumulative jiffies of one cpu line of /proc/stat, in the
+// order of the file: user, nice, system, idle, iowait, irq, softirq, steal
+type cpuTicks [8]uint64
+
+// Helper function to read the overall and per-core CPU ticks from /proc/stat
+func readCPUTicks(fsys fs.FS) (cpuTicks, []cpuTicks, error) {
+	content, err := fs.ReadFile(fsys, "proc/stat")
+	if err != nil {
+		return cpuTicks{}, nil, err
+	}
+	return parseCPUTicks(string(content))
+}
+
+// Helper function to parse the cpu lines of /proc/stat
+func parseCPUTicks(content string) (cpuTicks, []cpuTicks, error) {
+	var total cpuTicks
+	var cores []cpuTicks
+	found := false
+
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || !strings.HasPrefix(fields[0], "cpu") {
+			continue
+		}
+
+		// Older kernels have fewer columns, the missing ones stay zero
+		var ticks cpuTicks
+		for i := 0; i < len(ticks) && i+1 < len(fields); i++ {
+			value, err := strconv.ParseUint(fields[i+1], 10, 64)
+			if err != nil {
+				return cpuTicks{}, nil, fmt.Errorf("/proc/stat: %s: %w", fields[0], err)
+			}
+			ticks[i] = value
+		}
+
+		if fields[0] == "cpu" {
+			total = ticks
+			found = true
+		} else {
+			cores = append(cores, ticks)
+		}
+	}
+
+	if !found {
+		return cpuTicks{}, nil, fmt.Errorf("/proc/stat: no cpu line")
+	}
+	return total, cores, nil
+}
+
+// Helper function to turn the ticks elapsed between two samples into percentages
+func cpuTimesBetween(before, after cpuTicks) helper.CPUTimes {
+	delta := make([]float64, len(after))
+	var total float64
+	for i := range after {
+		// Counters of offlined cores can go backwards
+		if after[i] > before[i] {
+			delta[i] = float64(after[i] - before[i])
+		}
+		total += delta[i]
+	}
+	if total == 0 {
+		return helper.CPUTimes{Idle: 100}
+	}
+
+	percent := func(i int) float64 { return delta[i] / total * 100 }
+	return helper.CPUTimes{
+		User:    percent(0),
+		Nice:    percent(1),
+		System:  percent(2),
+		Idle:    percent(3),
+		IOWait:  percent(4),
+		IRQ:     percent(5),
+		SoftIRQ: percent(6),
+		Steal:   percent(7),
+	}
+}
+
+// Helper function to get the share of time a CPU was busy
+func busyPercent(times helper.CPUTimes) float64 {
+	return 100 - times.Idle - times.IOWait
+}
+
+// Helper function to get CPU usage by sampling /proc/stat twice. The
+// "interval" setting of the performance collector sets the time between the
+// samples.
+func getCPUUsage(ctx context.Context, fsys fs.FS) (helper.PerformanceInfo, error) {
+	interval, err := helper.CollectorSettings(ctx).Duration("interval", defaultSampleInterval)
+	if err != nil {
+		return helper.PerformanceInfo{}, err
+	}
+
+	totalBefore, coresBefore, err := readCPUTicks(fsys)
+	if err != nil {
+		return helper.PerformanceInfo{}, err
+	}
+
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		return helper.PerformanceInfo{}, ctx.Err()
+	}
+
+	totalAfter, coresAfter, err := readCPUTicks(fsys)
+	if err != nil {
+		return helper.PerformanceInfo{}, err
+	}
+
+	performance := helper.PerformanceInfo{
+		CPUTimes:       cpuTimesBetween(totalBefore, totalAfter),
+		SampleInterval: interval,
+	}
+	performance.CPUUsage = busyPercent(performance.CPUTimes)
+
+	// A core going offline between the samples shifts the lines, so the
+	// per-core figures are left out rather than compared with the wrong core
+	if len(coresBefore) != len(coresAfter) {
+		return performance, nil
+	}
+	for i := range coresAfter {
+		times := cpuTimesBetween(coresBefore[i], coresAfter[i])
+		performance.PerCoreTimes = append(performance.PerCoreTimes, times)
+		performance.PerCoreUsage = append(performance.PerCoreUsage, busyPercent(times))
+	}
+
+	return performance, nil
+}
@@ -0,0 +1,55 @@
+package linux
+
+import (
+	"testing"
+)
+
+func TestReadCPUTicks(t *testing.T) {
+	total, cores, err := readCPUTicks(fixture("laptop"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (cpuTicks{4000, 100, 2000, 90000, 500, 0, 100, 0}); total != want {
+		t.Errorf("total = %v, want %v", total, want)
+	}
+	if len(cores) != 4 {
+		t.Errorf("got %d cores, want 4", len(cores))
+	}
+
+	if _, _, err := readCPUTicks(fixture("convertible")); err == nil {
+		t.Error("no error without /proc/stat")
+	}
+}
+
+func TestCPUTimesBetween(t *testing.T) {
+	before := cpuTicks{100, 0, 100, 700, 100, 0, 0, 0}
+	after := cpuTicks{300, 0, 200, 1300, 200, 0, 0, 0}
+
+	times := cpuTimesBetween(before, after)
+	if times.User != 20 || times.System != 10 || times.Idle != 60 || times.IOWait != 10 {
+		t.Errorf("got %+v", times)
+	}
+	if got := busyPercent(times); got != 30 {
+		t.Errorf("busyPercent = %v, want 30", got)
+	}
+
+	// Without any ticks in between the CPU counts as idle
+	if got := cpuTimesBetween(after, after); got.Idle != 100 {
+		t.Errorf("got %+v for identical samples", got)
+	}
+}
+
+func TestParseCPUTicksOldKernel(t *testing.T) {
+	// Kernels before 2.6.11 have no steal column
+	total, _, err := parseCPUTicks("cpu 1 2 3 4 5 6 7\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (cpuTicks{1, 2, 3, 4, 5, 6, 7, 0}); total != want {
+		t.Errorf("got %v, want %v", total, want)
+	}
+
+	if _, _, err := parseCPUTicks("cpu 1 x\n"); err == nil {
+		t.Error("no error for a malformed cpu line")
+	}
+}
@@ -0,0 +1,387 @@
+package linux
+
+import (
+	"bufio"
+	"bytes"
+	"defetch/helper"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// Route flags from include/uapi/linux/route.h and ipv6_route.h
+const (
+	rtfGateway = 0x0002
+	rtfReject  = 0x0200
+	rtfLocal   = 0x80000000
+)
+
+// Attributes and actions of policy routing rules from include/uapi/linux/fib_rules.h
+const (
+	fraDst      = 1
+	fraSrc      = 2
+	fraIIFName  = 3
+	fraPriority = 6
+	fraFWMark   = 10
+	fraTable    = 15
+	fraOIFName  = 17
+
+	sizeofFibRuleHdr = 12
+)
+
+var ruleActions = map[uint8]string{
+	1: "lookup",
+	2: "goto",
+	3: "nop",
+	6: "blackhole",
+	7: "unreachable",
+	8: "prohibit",
+}
+
+// Files naming routing tables, with the tables of the packaged file overridden
+// by those of the administrator
+var tableFiles = []string{
+	"usr/lib/iproute2/rt_tables",
+	"usr/share/iproute2/rt_tables",
+	"etc/iproute2/rt_tables",
+}
+
+// resolvedStub is the address systemd-resolved listens on for resolv.conf
+const resolvedStub = "127.0.0.53"
+
+// Helper function to get the routing tables, the default gateways, the policy
+// routing rules and the DNS configuration
+func getRoutingInfo(fsys fs.FS) (helper.RoutingInfo, error) {
+	var routing helper.RoutingInfo
+
+	ipv4, ipv4Err := readIPv4Routes(fsys)
+	ipv6, ipv6Err := readIPv6Routes(fsys)
+	routing.Routes = append(ipv4, ipv6...)
+	routing.DefaultGateways = defaultGateways(routing.Routes)
+
+	dns, dnsErr := getDNSInfo(fsys)
+	routing.DNS = dns
+
+	// Rules are only reported over netlink, which knows the running system alone
+	var rulesErr error
+	if helper.IsLive(fsys) {
+		routing.Rules, rulesErr = getRules(readTableNames(fsys))
+		if rulesErr != nil {
+			rulesErr = fmt.Errorf("netlink: %w", rulesErr)
+		}
+	}
+
+	return routing, errors.Join(ipv4Err, ipv6Err, dnsErr, rulesErr)
+}
+
+// Helper function to read the IPv4 routes of the main table from /proc/net/route
+func readIPv4Routes(fsys fs.FS) ([]helper.RouteInfo, error) {
+	content, err := fs.ReadFile(fsys, "proc/net/route")
+	if err != nil {
+		return nil, err
+	}
+
+	var routes []helper.RouteInfo
+	for _, line := range strings.Split(string(content), "\n")[1:] {
+		// Iface Destination Gateway Flags RefCnt Use Metric Mask MTU Window IRTT
+		fields := strings.Fields(line)
+		if len(fields) < 8 {
+			continue
+		}
+		flags, _ := strconv.ParseUint(fields[3], 16, 32)
+		if flags&rtfReject != 0 {
+			continue
+		}
+		destination, mask := parseHexIPv4(fields[1]), parseHexIPv4(fields[7])
+		if destination == nil || mask == nil {
+			continue
+		}
+
+		route := helper.RouteInfo{
+			Family:      "ipv4",
+			Destination: (&net.IPNet{IP: destination, Mask: net.IPMask(mask)}).String(),
+			Interface:   fields[0],
+			Metric:      atoi(fields[6]),
+		}
+		if gateway := parseHexIPv4(fields[2]); flags&rtfGateway != 0 && gateway != nil {
+			route.Gateway = gateway.String()
+		}
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
+
+// Helper function to read the IPv6 routes from /proc/net/ipv6_route, leaving
+// out those of the local table
+func readIPv6Routes(fsys fs.FS) ([]helper.RouteInfo, error) {
+	content, err := fs.ReadFile(fsys, "proc/net/ipv6_route")
+	// Kernels without IPv6 have no such file
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var routes []helper.RouteInfo
+	for _, line := range strings.Split(string(content), "\n") {
+		// Destination PrefixLength Source PrefixLength NextHop Metric RefCnt Use Flags Iface
+		fields := strings.Fields(line)
+		if len(fields) < 10 {
+			continue
+		}
+		destination, _ := hex.DecodeString(fields[0])
+		prefixLength, _ := strconv.ParseUint(fields[1], 16, 8)
+		gateway, _ := hex.DecodeString(fields[4])
+		metric, _ := strconv.ParseUint(fields[5], 16, 32)
+		flags, _ := strconv.ParseUint(fields[8], 16, 32)
+		if len(destination) != net.IPv6len || len(gateway) != net.IPv6len {
+			continue
+		}
+		// Addresses of the system, multicast and unreachable routes belong to
+		// the kernel rather than the configuration
+		if flags&(rtfReject|rtfLocal) != 0 || net.IP(destination).IsMulticast() {
+			continue
+		}
+
+		route := helper.RouteInfo{
+			Family:      "ipv6",
+			Destination: (&net.IPNet{IP: destination, Mask: net.CIDRMask(int(prefixLength), 128)}).String(),
+			Interface:   fields[9],
+			Metric:      int(metric),
+		}
+		if flags&rtfGateway != 0 {
+			route.Gateway = net.IP(gateway).String()
+		}
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
+
+// Helper function to pick the default routes through a gateway, IPv4 first
+// and the preferred route of each family first
+func defaultGateways(routes []helper.RouteInfo) []helper.GatewayInfo {
+	var gateways []helper.GatewayInfo
+	for _, route := range routes {
+		if route.Gateway == "" || route.Destination != "0.0.0.0/0" && route.Destination != "::/0" {
+			continue
+		}
+		gateways = append(gateways, helper.GatewayInfo{
+			Family:    route.Family,
+			Gateway:   route.Gateway,
+			Interface: route.Interface,
+			Metric:    route.Metric,
+		})
+	}
+	sort.SliceStable(gateways, func(i, j int) bool {
+		if gateways[i].Family != gateways[j].Family {
+			return gateways[i].Family == "ipv4"
+		}
+		return gateways[i].Metric < gateways[j].Metric
+	})
+	return gateways
+}
+
+// Helper function to get the policy routing rules of both families
+func getRules(tables map[int]string) ([]helper.RuleInfo, error) {
+	data, err := syscall.NetlinkRIB(syscall.RTM_GETRULE, syscall.AF_UNSPEC)
+	if err != nil {
+		return nil, err
+	}
+	return parseRules(data, tables)
+}
+
+// Helper function to parse the RTM_NEWRULE messages of a netlink rule dump
+func parseRules(data []byte, tables map[int]string) ([]helper.RuleInfo, error) {
+	messages, err := syscall.ParseNetlinkMessage(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []helper.RuleInfo
+dump:
+	for _, message := range messages {
+		switch message.Header.Type {
+		case syscall.NLMSG_DONE:
+			break dump
+		case syscall.NLMSG_ERROR:
+			return nil, errors.New("rule dump failed")
+		case syscall.RTM_NEWRULE:
+		default:
+			continue
+		}
+		if len(message.Data) < sizeofFibRuleHdr {
+			continue
+		}
+
+		// struct fib_rule_hdr: family, dst_len, src_len, tos, table, res1, res2, action, flags
+		header := message.Data[:sizeofFibRuleHdr]
+		attrs := parseAttributes(message.Data[sizeofFibRuleHdr:])
+
+		rule := helper.RuleInfo{
+			Source:          prefix(attrs[fraSrc], header[2]),
+			Destination:     prefix(attrs[fraDst], header[1]),
+			InputInterface:  cString(attrs[fraIIFName]),
+			OutputInterface: cString(attrs[fraOIFName]),
+			Action:          ruleActions[header[7]],
+		}
+		switch header[0] {
+		case syscall.AF_INET:
+			rule.Family = "ipv4"
+		case syscall.AF_INET6:
+			rule.Family = "ipv6"
+		default:
+			// Rules of the multicast routing tables (RTNL_FAMILY_IPMR, RTNL_FAMILY_IP6MR) aren't reported
+			continue
+		}
+		if rule.Action == "" {
+			rule.Action = strconv.Itoa(int(header[7]))
+		}
+		if value := attrs[fraPriority]; len(value) == 4 {
+			rule.Priority = int(binary.NativeEndian.Uint32(value))
+		}
+		if value := attrs[fraFWMark]; len(value) == 4 {
+			rule.FWMark = binary.NativeEndian.Uint32(value)
+		}
+
+		// Table IDs above 255 don't fit the header and only come as an attribute
+		table := int(header[4])
+		if value := attrs[fraTable]; len(value) == 4 {
+			table = int(binary.NativeEndian.Uint32(value))
+		}
+		if rule.Action == "lookup" {
+			rule.Table = tables[table]
+			if rule.Table == "" {
+				rule.Table = strconv.Itoa(table)
+			}
+		}
+
+		rules = append(rules, rule)
+	}
+
+	// The dump is not in the order the kernel evaluates the rules
+	sort.SliceStable(rules, func(i, j int) bool {
+		if rules[i].Family != rules[j].Family {
+			return rules[i].Family == "ipv4"
+		}
+		return rules[i].Priority < rules[j].Priority
+	})
+	return rules, nil
+}
+
+// Helper function to format the address of a netlink attribute as a network
+func prefix(address []byte, length uint8) string {
+	if len(address) != net.IPv4len && len(address) != net.IPv6len {
+		return ""
+	}
+	return (&net.IPNet{IP: address, Mask: net.CIDRMask(int(length), len(address)*8)}).String()
+}
+
+// Helper function to read a NUL-terminated string attribute
+func cString(value []byte) string {
+	name, _, _ := bytes.Cut(value, []byte{0})
+	return string(name)
+}
+
+// Helper function to get the names of the routing tables by their ID
+func readTableNames(fsys fs.FS) map[int]string {
+	// Reserved in include/uapi/linux/rtnetlink.h
+	tables := map[int]string{253: "default", 254: "main", 255: "local"}
+
+	files := slices.Clone(tableFiles)
+	if entries, err := fs.ReadDir(fsys, "etc/iproute2/rt_tables.d"); err == nil {
+		for _, entry := range entries {
+			if strings.HasSuffix(entry.Name(), ".conf") {
+				files = append(files, path.Join("etc/iproute2/rt_tables.d", entry.Name()))
+			}
+		}
+	}
+
+	for _, name := range files {
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(content), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+				continue
+			}
+			if id, err := strconv.ParseUint(fields[0], 0, 32); err == nil {
+				tables[int(id)] = fields[1]
+			}
+		}
+	}
+	return tables
+}
+
+// Helper function to get the resolver configuration. When resolv.conf points
+// to the stub of systemd-resolved, the servers it forwards to are reported.
+func getDNSInfo(fsys fs.FS) (helper.DNSInfo, error) {
+	name := followLink(fsys, "etc/resolv.conf")
+	content, err := fs.ReadFile(fsys, name)
+	// Without resolv.conf the resolver queries the local host
+	if errors.Is(err, fs.ErrNotExist) {
+		return helper.DNSInfo{}, nil
+	}
+	if err != nil {
+		return helper.DNSInfo{}, err
+	}
+
+	dns := parseResolvConf(content)
+	dns.Resolver = "resolv.conf"
+	if path.Base(name) == "stub-resolv.conf" || slices.Equal(dns.Nameservers, []string{resolvedStub}) {
+		dns.Resolver = "systemd-resolved"
+		if upstream, err := fs.ReadFile(fsys, "run/systemd/resolve/resolv.conf"); err == nil {
+			dns.Nameservers = parseResolvConf(upstream).Nameservers
+		}
+	}
+	return dns, nil
+}
+
+// Helper function to parse the nameserver, search, domain and options lines
+// of resolv.conf
+func parseResolvConf(content []byte) helper.DNSInfo {
+	var dns helper.DNSInfo
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") || strings.HasPrefix(fields[0], ";") {
+			continue
+		}
+		switch fields[0] {
+		case "nameserver":
+			dns.Nameservers = append(dns.Nameservers, fields[1])
+		// The last search or domain line wins
+		case "search":
+			dns.SearchDomains = fields[1:]
+		case "domain":
+			dns.SearchDomains = fields[1:2]
+		case "options":
+			dns.Options = append(dns.Options, fields[1:]...)
+		}
+	}
+	return dns
+}
+
+// Helper function to resolve a symbolic link within fsys, so absolute links
+// below another root stay inside of it
+func followLink(fsys fs.FS, name string) string {
+	target, err := helper.ReadLink(fsys, name)
+	if err != nil {
+		return name
+	}
+	if path.IsAbs(target) {
+		return strings.TrimPrefix(path.Clean(target), "/")
+	}
+	return path.Join(path.Dir(name), target)
+}
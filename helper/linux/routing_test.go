@@ -0,0 +1,107 @@
+package linux
+
+import (
+	"defetch/helper"
+	"reflect"
+	"testing"
+)
+
+func TestRoutingInfo(t *testing.T) {
+	routing, err := getRoutingInfo(fixture("laptop"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Routes of the local table, multicast and unreachable routes are left out
+	wantRoutes := []helper.RouteInfo{
+		{Family: "ipv4", Destination: "0.0.0.0/0", Gateway: "192.168.1.1", Interface: "wlp0s20f3", Metric: 600},
+		{Family: "ipv4", Destination: "172.17.0.0/16", Interface: "docker0"},
+		{Family: "ipv4", Destination: "192.168.1.0/24", Interface: "wlp0s20f3", Metric: 600},
+		{Family: "ipv4", Destination: "0.0.0.0/0", Interface: "tun0", Metric: 50},
+		{Family: "ipv6", Destination: "2001:db8::/64", Interface: "wlp0s20f3", Metric: 600},
+		{Family: "ipv6", Destination: "fe80::/64", Interface: "wlp0s20f3", Metric: 1024},
+		{Family: "ipv6", Destination: "::/0", Gateway: "fe80::1", Interface: "wlp0s20f3", Metric: 600},
+	}
+	if !reflect.DeepEqual(routing.Routes, wantRoutes) {
+		t.Errorf("got routes %+v, want %+v", routing.Routes, wantRoutes)
+	}
+
+	// The default route of tun0 has no gateway
+	wantGateways := []helper.GatewayInfo{
+		{Family: "ipv4", Gateway: "192.168.1.1", Interface: "wlp0s20f3", Metric: 600},
+		{Family: "ipv6", Gateway: "fe80::1", Interface: "wlp0s20f3", Metric: 600},
+	}
+	if !reflect.DeepEqual(routing.DefaultGateways, wantGateways) {
+		t.Errorf("got gateways %+v, want %+v", routing.DefaultGateways, wantGateways)
+	}
+
+	// resolv.conf links to the stub, so the servers come from the copy of systemd-resolved
+	wantDNS := helper.DNSInfo{
+		Resolver:      "systemd-resolved",
+		Nameservers:   []string{"192.168.1.1", "fd00::1"},
+		SearchDomains: []string{"fritz.box", "corp.example.com"},
+		Options:       []string{"edns0", "trust-ad"},
+	}
+	if !reflect.DeepEqual(routing.DNS, wantDNS) {
+		t.Errorf("got DNS %+v, want %+v", routing.DNS, wantDNS)
+	}
+
+	// Rules are only known over netlink
+	if routing.Rules != nil {
+		t.Errorf("got rules %+v below another root", routing.Rules)
+	}
+}
+
+func TestDefaultGatewaysByMetric(t *testing.T) {
+	routes := []helper.RouteInfo{
+		{Family: "ipv6", Destination: "::/0", Gateway: "fe80::1", Interface: "eth0", Metric: 1024},
+		{Family: "ipv4", Destination: "0.0.0.0/0", Gateway: "192.168.1.1", Interface: "wlan0", Metric: 600},
+		{Family: "ipv4", Destination: "10.0.0.0/8", Gateway: "10.0.0.1", Interface: "eth0"},
+		{Family: "ipv4", Destination: "0.0.0.0/0", Gateway: "10.0.0.1", Interface: "eth0", Metric: 100},
+	}
+	want := []helper.GatewayInfo{
+		{Family: "ipv4", Gateway: "10.0.0.1", Interface: "eth0", Metric: 100},
+		{Family: "ipv4", Gateway: "192.168.1.1", Interface: "wlan0", Metric: 600},
+		{Family: "ipv6", Gateway: "fe80::1", Interface: "eth0", Metric: 1024},
+	}
+	if got := defaultGateways(routes); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	// An RTM_GETRULE dump in little-endian byte order, with a rule of the IPv4
+	// multicast routing tables before the end that must be left out
+	got, err := parseRules(readNetlink(t, "getrule-laptop.bin"), readTableNames(fixture("laptop")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []helper.RuleInfo{
+		{Family: "ipv4", Priority: 0, Action: "lookup", Table: "local"},
+		{Family: "ipv4", Priority: 100, Source: "10.8.0.0/24", Action: "lookup", Table: "vpn"},
+		// Table IDs above 255 are named by rt_tables.d
+		{Family: "ipv4", Priority: 5000, FWMark: 0xca6c, Action: "lookup", Table: "wireguard"},
+		{Family: "ipv4", Priority: 6000, Destination: "10.8.0.0/24", InputInterface: "docker0", Action: "prohibit"},
+		{Family: "ipv4", Priority: 32766, Action: "lookup", Table: "main"},
+		{Family: "ipv4", Priority: 32767, Action: "lookup", Table: "default"},
+		{Family: "ipv6", Priority: 0, Action: "lookup", Table: "local"},
+		{Family: "ipv6", Priority: 32766, Action: "lookup", Table: "main"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestParseResolvConf(t *testing.T) {
+	content := []byte("; written by hand\nnameserver 9.9.9.9\nsearch example.org\ndomain example.com\noptions ndots:2\noptions rotate\n")
+	want := helper.DNSInfo{
+		Nameservers: []string{"9.9.9.9"},
+		// The last search or domain line wins
+		SearchDomains: []string{"example.com"},
+		Options:       []string{"ndots:2", "rotate"},
+	}
+	if got := parseResolvConf(content); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,222 @@
+package linux
+
+import (
+	"context"
+	"defetch/helper"
+	"errors"
+	"io/fs"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// blockDir lists the disks, with their partitions as subdirectories
+const blockDir = "sys/block"
+
+// sectorSize is the unit of sizes in sysfs, whatever the disk's own sector size
+const sectorSize = 512
+
+// Helper function to get each disk with its partitions, the file systems
+// mounted from them and their activity
+func getStorageInfo(ctx context.Context, fsys fs.FS) ([]helper.StorageInfo, error) {
+	io, ioErr := sampleDiskStats(ctx, fsys)
+	storages, err := getDisks(fsys)
+	for i := range storages {
+		storages[i].IO = io[storages[i].Device]
+	}
+	return storages, errors.Join(err, ioErr)
+}
+
+// Helper function to get each disk with its partitions and the file systems
+// mounted from them
+func getDisks(fsys fs.FS) ([]helper.StorageInfo, error) {
+	entries, err := fs.ReadDir(fsys, blockDir)
+	if err != nil {
+		return nil, err
+	}
+	mounts, mountsErr := readMounts(fsys)
+	swaps := readSwaps(fsys)
+
+	var storages []helper.StorageInfo
+	for _, entry := range entries {
+		disk := getDisk(fsys, entry.Name(), mounts, swaps)
+		// Loop devices without a file and drives without a medium are empty
+		if disk.Capacity == 0 {
+			continue
+		}
+		storages = append(storages, disk)
+	}
+	return storages, mountsErr
+}
+
+// Helper function to read a disk and its partitions from sysfs
+func getDisk(fsys fs.FS, name string, mounts mountTable, swaps map[string]bool) helper.StorageInfo {
+	dir := path.Join(blockDir, name)
+	disk := helper.StorageInfo{
+		Device:     name,
+		Model:      readSysfs(fsys, path.Join(dir, "device", "model")),
+		Vendor:     readSysfs(fsys, path.Join(dir, "device", "vendor")),
+		Serial:     readSysfs(fsys, path.Join(dir, "device", "serial")),
+		Capacity:   parseBytes(readSysfs(fsys, path.Join(dir, "size"))) * sectorSize,
+		Rotational: readSysfs(fsys, path.Join(dir, "queue", "rotational")) == "1",
+		Removable:  readSysfs(fsys, path.Join(dir, "removable")) == "1",
+		Mounts:     mounts.of(fsys, name, readSysfs(fsys, path.Join(dir, "dev"))),
+	}
+	// virtio disks report the ID of their vendor rather than a name
+	if strings.HasPrefix(disk.Vendor, "0x") {
+		disk.Vendor = ""
+	}
+	if len(disk.Mounts) > 0 {
+		disk.Used, disk.Available = disk.Mounts[0].Used, disk.Mounts[0].Available
+	}
+
+	entries, _ := fs.ReadDir(fsys, dir)
+	for _, entry := range entries {
+		partDir := path.Join(dir, entry.Name())
+		number := readSysfs(fsys, path.Join(partDir, "partition"))
+		if number == "" {
+			continue
+		}
+
+		partition := helper.PartitionInfo{
+			Device: entry.Name(),
+			Number: atoi(number),
+			Size:   parseBytes(readSysfs(fsys, path.Join(partDir, "size"))) * sectorSize,
+			Mounts: mounts.of(fsys, entry.Name(), readSysfs(fsys, path.Join(partDir, "dev"))),
+		}
+		// Bind mounts share the space of the first mount
+		if len(partition.Mounts) > 0 {
+			first := partition.Mounts[0]
+			partition.MountPoint = first.MountPoint
+			partition.Filesystem = first.FileSystem
+			partition.Used, partition.Available = first.Used, first.Available
+		} else if swaps[entry.Name()] {
+			partition.Filesystem = "swap"
+		}
+		disk.Used += partition.Used
+		disk.Available += partition.Available
+		disk.Partitions = append(disk.Partitions, partition)
+	}
+	sort.Slice(disk.Partitions, func(i, j int) bool {
+		return disk.Partitions[i].Number < disk.Partitions[j].Number
+	})
+
+	return disk
+}
+
+// mountTable holds the mounts of block devices by device number and by source
+type mountTable struct {
+	byDevice map[string][]helper.MountInfo
+	bySource map[string][]helper.MountInfo
+}
+
+// Helper function to get the mounts of a disk or partition, with their sizes
+// on the running system
+func (t mountTable) of(fsys fs.FS, name, device string) []helper.MountInfo {
+	mounts := t.byDevice[device]
+	// btrfs reports an anonymous device number for each subvolume
+	if len(mounts) == 0 {
+		mounts = t.bySource["/dev/"+name]
+	}
+	if !helper.IsLive(fsys) {
+		return mounts
+	}
+
+	mounts = append([]helper.MountInfo(nil), mounts...)
+	for i := range mounts {
+		var stat syscall.Statfs_t
+		if err := syscall.Statfs(mounts[i].MountPoint, &stat); err != nil {
+			continue
+		}
+		mounts[i].Size, mounts[i].Used, mounts[i].Available = statfsSizes(stat)
+	}
+	return mounts
+}
+
+// Helper function to get the size, used and available space from statfs
+func statfsSizes(stat syscall.Statfs_t) (size, used, available helper.Bytes) {
+	blockSize := helper.Bytes(stat.Frsize)
+	if blockSize == 0 {
+		blockSize = helper.Bytes(stat.Bsize)
+	}
+	return helper.Bytes(stat.Blocks) * blockSize, helper.Bytes(stat.Blocks-stat.Bfree) * blockSize, helper.Bytes(stat.Bavail) * blockSize
+}
+
+// Helper function to read the mounts of the collector's own mount namespace
+// from /proc/self/mountinfo
+func readMounts(fsys fs.FS) (mountTable, error) {
+	table := mountTable{
+		byDevice: make(map[string][]helper.MountInfo),
+		bySource: make(map[string][]helper.MountInfo),
+	}
+	content, err := fs.ReadFile(fsys, "proc/self/mountinfo")
+	if err != nil {
+		return table, err
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		// ID ParentID Major:Minor Root MountPoint Options [Optional...] - Type Source SuperOptions
+		before, after, found := strings.Cut(line, " - ")
+		fields, fsFields := strings.Fields(before), strings.Fields(after)
+		if !found || len(fields) < 6 || len(fsFields) < 3 {
+			continue
+		}
+
+		mount := helper.MountInfo{
+			MountPoint: unescapeMount(fields[4]),
+			FileSystem: fsFields[0],
+			Source:     unescapeMount(fsFields[1]),
+			Root:       unescapeMount(fields[3]),
+		}
+		for _, option := range append(strings.Split(fields[5], ","), strings.Split(fsFields[2], ",")...) {
+			if !slices.Contains(mount.Options, option) {
+				mount.Options = append(mount.Options, option)
+			}
+		}
+
+		table.byDevice[fields[2]] = append(table.byDevice[fields[2]], mount)
+		table.bySource[mount.Source] = append(table.bySource[mount.Source], mount)
+	}
+	return table, nil
+}
+
+// Helper function to decode the octal escapes of spaces, tabs, newlines and
+// backslashes in mountinfo
+func unescapeMount(value string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+3 < len(value) {
+			if c, err := strconv.ParseUint(value[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(c))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(value[i])
+	}
+	return b.String()
+}
+
+// Helper function to get the names of the block devices in use as swap
+func readSwaps(fsys fs.FS) map[string]bool {
+	content, err := fs.ReadFile(fsys, "proc/swaps")
+	if err != nil {
+		return nil
+	}
+
+	swaps := make(map[string]bool)
+	for _, line := range strings.Split(string(content), "\n")[1:] {
+		// Filename Type Size Used Priority
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[1] == "partition" {
+			swaps[path.Base(fields[0])] = true
+		}
+	}
+	return swaps
+}
@@ -0,0 +1,83 @@
+package linux
+
+import (
+	"defetch/helper"
+	"reflect"
+	"testing"
+)
+
+func TestStorageInfo(t *testing.T) {
+	storages, err := getDisks(fixture("laptop"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root := helper.MountInfo{MountPoint: "/", FileSystem: "ext4", Source: "/dev/nvme0n1p2", Root: "/", Options: []string{"rw", "relatime", "errors=remount-ro"}}
+	srv := helper.MountInfo{MountPoint: "/home/alice/srv", FileSystem: "ext4", Source: "/dev/nvme0n1p2", Root: "/srv", Options: []string{"rw", "relatime", "errors=remount-ro"}}
+	efi := helper.MountInfo{MountPoint: "/boot/efi", FileSystem: "vfat", Source: "/dev/nvme0n1p1", Root: "/", Options: []string{
+		"rw", "relatime", "fmask=0077", "dmask=0077", "codepage=437", "iocharset=ascii", "shortname=mixed", "utf8", "errors=remount-ro",
+	}}
+	stick := helper.MountInfo{MountPoint: "/media/alice/USB STICK", FileSystem: "vfat", Source: "/dev/sda", Root: "/", Options: []string{
+		"rw", "nosuid", "nodev", "relatime", "fmask=0022", "dmask=0022", "codepage=437", "iocharset=iso8859-1", "shortname=mixed", "showexec", "utf8", "flush", "errors=remount-ro",
+	}}
+
+	// The empty loop device is left out, and sizes need statfs on the running system
+	want := []helper.StorageInfo{
+		{
+			Device:   "nvme0n1",
+			Model:    "SAMSUNG MZVLB512HBJQ-000L7",
+			Serial:   "S4GENX0N123456",
+			Capacity: 512110190592,
+			Partitions: []helper.PartitionInfo{
+				{Device: "nvme0n1p1", Number: 1, MountPoint: "/boot/efi", Filesystem: "vfat", Size: 537919488, Mounts: []helper.MountInfo{efi}},
+				{Device: "nvme0n1p2", Number: 2, MountPoint: "/", Filesystem: "ext4", Size: 502981984256, Mounts: []helper.MountInfo{root, srv}},
+				{Device: "nvme0n1p3", Number: 3, Filesystem: "swap", Size: 8588886016},
+			},
+		},
+		{
+			Device:     "sda",
+			Model:      "Ultra USB 3.0",
+			Vendor:     "SanDisk",
+			Capacity:   31914983424,
+			Rotational: true,
+			Removable:  true,
+			Mounts:     []helper.MountInfo{stick},
+		},
+		{Device: "zram0", Capacity: 8589934592},
+	}
+	if !reflect.DeepEqual(storages, want) {
+		t.Errorf("got %+v, want %+v", storages, want)
+	}
+}
+
+func TestStorageInfoBtrfs(t *testing.T) {
+	storages, err := getDisks(fixture("convertible"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(storages) != 1 || len(storages[0].Partitions) != 2 {
+		t.Fatalf("got %+v", storages)
+	}
+
+	// Subvolumes have anonymous device numbers and are found by their source
+	partition := storages[0].Partitions[1]
+	if partition.MountPoint != "/" || partition.Filesystem != "btrfs" || len(partition.Mounts) != 2 {
+		t.Errorf("got %+v", partition)
+	}
+	if home := partition.Mounts[1]; home.MountPoint != "/home" || home.Root != "/@home" {
+		t.Errorf("got %+v for /home", home)
+	}
+}
+
+func TestUnescapeMount(t *testing.T) {
+	tests := map[string]string{
+		`/media/alice/USB\040STICK`: "/media/alice/USB STICK",
+		`/mnt/tab\011and\134slash`:  "/mnt/tab\tand\\slash",
+		`/mnt/trailing\04`:          `/mnt/trailing\04`,
+	}
+	for value, want := range tests {
+		if got := unescapeMount(value); got != want {
+			t.Errorf("%s: got %q, want %q", value, got, want)
+		}
+	}
+}
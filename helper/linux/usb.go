@@ -0,0 +1,129 @@
+package linux
+
+import (
+	"defetch/helper"
+	"defetch/helper/ids"
+	"io/fs"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// usbDevicesDir lists every USB device and interface known to the kernel
+const usbDevicesDir = "sys/bus/usb/devices"
+
+// usbDevices is the value of the usb collector, which GetLinuxInfo adds to the
+// peripherals section
+type usbDevices []helper.USBDeviceInfo
+
+// Helper function to get the USB devices from sysfs, naming them after usb.ids
+// or else after the strings the devices report. Devices are ordered by bus and
+// port so that hubs come right before the devices plugged into them.
+func getUSBDevices(fsys fs.FS) (usbDevices, error) {
+	entries, err := fs.ReadDir(fsys, usbDevicesDir)
+	if err != nil {
+		return nil, err
+	}
+
+	usb := ids.USB(fsys)
+	var devices usbDevices
+	for _, entry := range entries {
+		// Interfaces like 1-1:1.0 have no IDs of their own
+		vendorID := readSysfs(fsys, path.Join(usbDevicesDir, entry.Name(), "idVendor"))
+		if vendorID == "" {
+			continue
+		}
+		devices = append(devices, getUSBDevice(fsys, usb, entry.Name(), vendorID))
+	}
+
+	sort.Slice(devices, func(i, j int) bool {
+		return usbLess(devices[i], devices[j])
+	})
+	return devices, nil
+}
+
+// Helper function to read the attributes of one USB device
+func getUSBDevice(fsys fs.FS, usb *ids.Database, name, vendorID string) helper.USBDeviceInfo {
+	dir := path.Join(usbDevicesDir, name)
+	attr := func(name string) string {
+		return readSysfs(fsys, path.Join(dir, name))
+	}
+
+	device := helper.USBDeviceInfo{
+		Path:         name,
+		VendorID:     vendorID,
+		ProductID:    attr("idProduct"),
+		Bus:          atoi(attr("busnum")),
+		DeviceNumber: atoi(attr("devnum")),
+		Version:      attr("version"),
+		ClassID:      attr("bDeviceClass"),
+		Serial:       attr("serial"),
+		MaxPowerMA:   atoi(strings.TrimSuffix(attr("bMaxPower"), "mA")),
+	}
+	device.SpeedMbps, _ = strconv.ParseFloat(attr("speed"), 64)
+
+	device.Vendor = usb.Vendor(device.VendorID)
+	if device.Vendor == "" {
+		device.Vendor = attr("manufacturer")
+	}
+	device.Name = usb.Device(device.VendorID, device.ProductID)
+	if device.Name == "" {
+		device.Name = attr("product")
+	}
+
+	// Root hubs are called usb<bus>, other devices <bus>-<port>.<port>...
+	if _, port, found := strings.Cut(name, "-"); found {
+		device.Port = port
+	}
+	// The device links into the directory of its hub, or of the host
+	// controller for root hubs
+	if target, err := helper.ReadLink(fsys, dir); err == nil {
+		parent := path.Base(path.Dir(target))
+		if _, err := fs.Stat(fsys, path.Join(usbDevicesDir, parent, "idVendor")); err == nil {
+			device.Parent = parent
+		}
+	}
+
+	// Interfaces are named <device>:<config>.<interface> and carry the drivers
+	interfaces, _ := fs.Glob(fsys, path.Join(usbDevicesDir, name+":*"))
+	sort.Strings(interfaces)
+	for _, iface := range interfaces {
+		// Devices of class 00 define their class per interface
+		if device.ClassID == "00" {
+			device.ClassID = readSysfs(fsys, path.Join(iface, "bInterfaceClass"))
+		}
+		driver, err := helper.ReadLink(fsys, path.Join(iface, "driver"))
+		if err != nil {
+			continue
+		}
+		driverName := path.Base(driver)
+		if !slices.Contains(device.Drivers, driverName) {
+			device.Drivers = append(device.Drivers, driverName)
+		}
+	}
+	device.Class = usb.Class(device.ClassID)
+
+	return device
+}
+
+// Helper function to order USB devices by bus, then numerically by port path
+func usbLess(a, b helper.USBDeviceInfo) bool {
+	if a.Bus != b.Bus {
+		return a.Bus < b.Bus
+	}
+	portsA, portsB := strings.Split(a.Port, "."), strings.Split(b.Port, ".")
+	for i := 0; i < len(portsA) && i < len(portsB); i++ {
+		if portsA[i] != portsB[i] {
+			return atoi(portsA[i]) < atoi(portsB[i])
+		}
+	}
+	return len(portsA) < len(portsB)
+}
+
+// Helper function to parse a decimal sysfs value, returning 0 when it is missing
+func atoi(value string) int {
+	n, _ := strconv.Atoi(strings.TrimSpace(value))
+	return n
+}
@@ -0,0 +1,77 @@
+package linux
+
+import (
+	"defetch/helper"
+	"reflect"
+	"testing"
+)
+
+func TestUSBDevices(t *testing.T) {
+	devices, err := getUSBDevices(fixture("laptop"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(devices) != 2 {
+		t.Fatalf("got %d devices, want 2: %+v", len(devices), devices)
+	}
+
+	want := usbDevices{
+		{
+			Name:         "2.0 root hub",
+			Vendor:       "Linux Foundation",
+			ProductID:    "0002",
+			VendorID:     "1d6b",
+			Path:         "usb1",
+			Bus:          1,
+			DeviceNumber: 1,
+			Version:      "2.00",
+			SpeedMbps:    480,
+			ClassID:      "09",
+			Class:        "Hub",
+			Serial:       "0000:00:14.0",
+		},
+		{
+			Name:         "Unifying Receiver",
+			Vendor:       "Logitech, Inc.",
+			ProductID:    "c52b",
+			VendorID:     "046d",
+			Path:         "1-1",
+			Parent:       "usb1",
+			Bus:          1,
+			Port:         "1",
+			DeviceNumber: 2,
+			Version:      "2.00",
+			SpeedMbps:    12,
+			ClassID:      "03",
+			Class:        "Human Interface Device",
+			MaxPowerMA:   98,
+			Drivers:      []string{"usbhid"},
+		},
+	}
+	if !reflect.DeepEqual(devices, want) {
+		t.Errorf("got %+v, want %+v", devices, want)
+	}
+}
+
+func TestUSBDevicesWithoutSysfs(t *testing.T) {
+	// A missing /sys/bus/usb/devices is an error rather than no devices
+	if devices, err := getUSBDevices(fixture("missing")); err == nil {
+		t.Errorf("got %+v and no error", devices)
+	}
+}
+
+func TestUSBLess(t *testing.T) {
+	// Ports compare as numbers, and hubs come before what is plugged into them
+	ordered := []helper.USBDeviceInfo{
+		{Bus: 1, Port: ""},
+		{Bus: 1, Port: "2"},
+		{Bus: 1, Port: "2.1"},
+		{Bus: 1, Port: "10"},
+		{Bus: 2, Port: "1"},
+	}
+	for i := 0; i+1 < len(ordered); i++ {
+		if !usbLess(ordered[i], ordered[i+1]) || usbLess(ordered[i+1], ordered[i]) {
+			t.Errorf("%+v should sort before %+v", ordered[i], ordered[i+1])
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package linux
+
+import (
+	"defetch/helper"
+	"encoding/binary"
+	"io/fs"
+	"net"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// Commands and attributes from include/uapi/linux/nl80211.h
+const (
+	nl80211CmdGetInterface = 5
+	nl80211CmdGetStation   = 17
+	nl80211CmdGetReg       = 31
+
+	nl80211AttrWiphy        = 1
+	nl80211AttrIfindex      = 3
+	nl80211AttrMAC          = 6
+	nl80211AttrStaInfo      = 21
+	nl80211AttrRegAlpha2    = 33
+	nl80211AttrWiphyFreq    = 38
+	nl80211AttrSSID         = 52
+	nl80211AttrChannelWidth = 159
+
+	nl80211StaInfoSignal    = 7
+	nl80211StaInfoTXBitrate = 8
+	nl80211StaInfoRXBitrate = 14
+
+	nl80211RateInfoBitrate   = 1 // u16 in 100 kbit/s
+	nl80211RateInfoBitrate32 = 5 // u32 in 100 kbit/s, for rates above 6.5 Gbit/s
+)
+
+// Widths of enum nl80211_chan_width in MHz
+var channelWidths = map[uint32]int{
+	0:  20, // 20 MHz without HT
+	1:  20,
+	2:  40,
+	3:  80,
+	4:  160, // 80+80 MHz
+	5:  160,
+	6:  5,
+	7:  10,
+	8:  1,
+	9:  2,
+	10: 4,
+	11: 8,
+	12: 16,
+	13: 320,
+}
+
+// Helper function to get the Wi-Fi link of an interface over nl80211 on the
+// running system, or else from /proc/net/wireless
+func getWirelessInfo(fsys fs.FS, index int, name string) *helper.WirelessInfo {
+	if helper.IsLive(fsys) {
+		// Systems without cfg80211 loaded don't know the nl80211 family
+		if wireless, err := getNL80211Info(index); err == nil {
+			return wireless
+		}
+	}
+
+	wireless := &helper.WirelessInfo{}
+	if signal, ok := readProcWireless(fsys)[name]; ok {
+		wireless.SignalDBm = signal
+	}
+	return wireless
+}
+
+// Helper function to query the interface, the access point it is connected
+// to and the regulatory domain of its radio
+func getNL80211Info(index int) (*helper.WirelessInfo, error) {
+	family, err := genetlinkFamily("nl80211")
+	if err != nil {
+		return nil, err
+	}
+
+	ifindex := netlinkAttribute(nl80211AttrIfindex, binary.NativeEndian.AppendUint32(nil, uint32(index)))
+	iface, err := genetlinkRequest(family, nl80211CmdGetInterface, 0, ifindex)
+	if err != nil {
+		return nil, err
+	}
+	// Stations are only listed as a dump, where a client has its access point
+	stations, err := genetlinkRequest(family, nl80211CmdGetStation, syscall.NLM_F_DUMP, ifindex)
+	if err != nil {
+		return nil, err
+	}
+
+	// Radios managing their own regulatory domain report it for their wiphy,
+	// the others get the global domain
+	var regulatory []byte
+	if attrs, err := genetlinkAttributes(iface); err == nil && len(attrs) > 0 && len(attrs[0][nl80211AttrWiphy]) == 4 {
+		regulatory, _ = genetlinkRequest(family, nl80211CmdGetReg, 0, netlinkAttribute(nl80211AttrWiphy, attrs[0][nl80211AttrWiphy]))
+	}
+
+	return parseNL80211(iface, stations, regulatory)
+}
+
+// Helper function to build the Wi-Fi link from the replies to the nl80211
+// interface, station and regulatory requests
+func parseNL80211(iface, stations, regulatory []byte) (*helper.WirelessInfo, error) {
+	var wireless helper.WirelessInfo
+
+	replies, err := genetlinkAttributes(iface)
+	if err != nil {
+		return nil, err
+	}
+	for _, attrs := range replies {
+		// The SSID is only reported while connected
+		wireless.SSID = string(attrs[nl80211AttrSSID])
+		if value := attrs[nl80211AttrWiphyFreq]; len(value) == 4 {
+			wireless.FrequencyMHz = int(binary.NativeEndian.Uint32(value))
+			wireless.Band, wireless.Channel = frequencyChannel(wireless.FrequencyMHz)
+		}
+		if value := attrs[nl80211AttrChannelWidth]; len(value) == 4 {
+			wireless.ChannelWidthMHz = channelWidths[binary.NativeEndian.Uint32(value)]
+		}
+	}
+
+	replies, err = genetlinkAttributes(stations)
+	if err != nil {
+		return nil, err
+	}
+	for _, attrs := range replies {
+		if mac := attrs[nl80211AttrMAC]; len(mac) == 6 {
+			wireless.BSSID = net.HardwareAddr(mac).String()
+		}
+		info := parseAttributes(attrs[nl80211AttrStaInfo])
+		if value := info[nl80211StaInfoSignal]; len(value) == 1 {
+			wireless.SignalDBm = int(int8(value[0]))
+		}
+		wireless.TXBitrateMbps = bitrate(info[nl80211StaInfoTXBitrate])
+		wireless.RXBitrateMbps = bitrate(info[nl80211StaInfoRXBitrate])
+	}
+
+	replies, err = genetlinkAttributes(regulatory)
+	if err != nil {
+		return nil, err
+	}
+	for _, attrs := range replies {
+		wireless.RegulatoryDomain = cString(attrs[nl80211AttrRegAlpha2])
+	}
+
+	return &wireless, nil
+}
+
+// Helper function to get the rate of a nested nl80211_rate_info in Mbit/s
+func bitrate(value []byte) float64 {
+	info := parseAttributes(value)
+	if rate := info[nl80211RateInfoBitrate32]; len(rate) == 4 {
+		return float64(binary.NativeEndian.Uint32(rate)) / 10
+	}
+	if rate := info[nl80211RateInfoBitrate]; len(rate) == 2 {
+		return float64(binary.NativeEndian.Uint16(rate)) / 10
+	}
+	return 0
+}
+
+// Helper function to get the band and channel number of a frequency in MHz
+func frequencyChannel(frequency int) (string, int) {
+	switch {
+	case frequency == 2484:
+		return "2.4 GHz", 14
+	case frequency >= 2412 && frequency < 2484:
+		return "2.4 GHz", (frequency - 2407) / 5
+	// Channel 2 of the 6 GHz band lies below the others
+	case frequency == 5935:
+		return "6 GHz", 2
+	case frequency > 5950 && frequency <= 7115:
+		return "6 GHz", (frequency - 5950) / 5
+	// The 4.9 GHz channels of public safety bands are numbered from 4000 MHz
+	case frequency >= 4910 && frequency <= 4980:
+		return "5 GHz", (frequency - 4000) / 5
+	case frequency >= 5000 && frequency <= 5895:
+		return "5 GHz", (frequency - 5000) / 5
+	case frequency >= 58320 && frequency <= 70200:
+		return "60 GHz", (frequency - 56160) / 2160
+	}
+	return "", 0
+}
+
+// Helper function to get the signal level of each interface from
+// /proc/net/wireless, which wireless extensions still provide
+func readProcWireless(fsys fs.FS) map[string]int {
+	content, err := fs.ReadFile(fsys, "proc/net/wireless")
+	if err != nil {
+		return nil
+	}
+
+	signals := make(map[string]int)
+	lines := strings.Split(string(content), "\n")
+	for _, line := range lines[min(2, len(lines)):] {
+		// Interface: status link level noise ..., with trailing dots on the values
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		level, err := strconv.ParseFloat(strings.TrimSuffix(fields[3], "."), 64)
+		if err != nil {
+			continue
+		}
+		signals[strings.TrimSuffix(fields[0], ":")] = int(level)
+	}
+	return signals
+}
@@ -0,0 +1,77 @@
+package linux
+
+import (
+	"defetch/helper"
+	"reflect"
+	"testing"
+)
+
+func TestParseNL80211(t *testing.T) {
+	// Replies of a laptop connected at 5 GHz, in little-endian byte order
+	got, err := parseNL80211(
+		readNetlink(t, "nl80211-interface-laptop.bin"),
+		readNetlink(t, "nl80211-station-laptop.bin"),
+		readNetlink(t, "nl80211-reg-laptop.bin"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &helper.WirelessInfo{
+		SSID:             "HomeNet",
+		BSSID:            "f4:f2:6d:11:22:33",
+		FrequencyMHz:     5180,
+		Band:             "5 GHz",
+		Channel:          36,
+		ChannelWidthMHz:  80,
+		SignalDBm:        -52,
+		TXBitrateMbps:    866.7,
+		RXBitrateMbps:    780,
+		RegulatoryDomain: "DE",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	// A disconnected interface has no station and reports no network
+	got, err = parseNL80211(nil, nil, nil)
+	if err != nil || *got != (helper.WirelessInfo{}) {
+		t.Errorf("got %+v, %v without replies", got, err)
+	}
+}
+
+func TestFrequencyChannel(t *testing.T) {
+	tests := []struct {
+		frequency int
+		band      string
+		channel   int
+	}{
+		{2412, "2.4 GHz", 1},
+		{2484, "2.4 GHz", 14},
+		{4920, "5 GHz", 184},
+		{4940, "5 GHz", 188},
+		{4980, "5 GHz", 196},
+		{4990, "", 0},
+		{5180, "5 GHz", 36},
+		{5825, "5 GHz", 165},
+		{5935, "6 GHz", 2},
+		{5955, "6 GHz", 1},
+		{6115, "6 GHz", 33},
+		{60480, "60 GHz", 2},
+		{900, "", 0},
+	}
+	for _, test := range tests {
+		band, channel := frequencyChannel(test.frequency)
+		if band != test.band || channel != test.channel {
+			t.Errorf("%d MHz: got %q channel %d, want %q channel %d", test.frequency, band, channel, test.band, test.channel)
+		}
+	}
+}
+
+func TestWirelessFromProc(t *testing.T) {
+	// Below another root only /proc/net/wireless describes the link
+	got := getWirelessInfo(fixture("laptop"), 3, "wlp0s20f3")
+	if want := (&helper.WirelessInfo{SignalDBm: -52}); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,272 @@
+// Package logo renders a distribution logo side by side with a summary of the
+// collected system information, in the style of neofetch.
+package logo
+
+import (
+	"bufio"
+	"embed"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Color is one of the 16 standard terminal colours
+type Color int
+
+const (
+	Black Color = iota
+	Red
+	Green
+	Yellow
+	Blue
+	Magenta
+	Cyan
+	White
+	BrightBlack
+	BrightRed
+	BrightGreen
+	BrightYellow
+	BrightBlue
+	BrightMagenta
+	BrightCyan
+	BrightWhite
+)
+
+// escape returns the bold foreground escape sequence for c
+func (c Color) escape() string {
+	if c >= BrightBlack {
+		return fmt.Sprintf("\x1b[1;%dm", 90+int(c-BrightBlack))
+	}
+	return fmt.Sprintf("\x1b[1;%dm", 30+int(c))
+}
+
+const reset = "\x1b[0m"
+
+// colorNames maps the names accepted by ParseColor to colours
+var colorNames = map[string]Color{
+	"black":          Black,
+	"red":            Red,
+	"green":          Green,
+	"yellow":         Yellow,
+	"blue":           Blue,
+	"magenta":        Magenta,
+	"cyan":           Cyan,
+	"white":          White,
+	"bright-black":   BrightBlack,
+	"bright-red":     BrightRed,
+	"bright-green":   BrightGreen,
+	"bright-yellow":  BrightYellow,
+	"bright-blue":    BrightBlue,
+	"bright-magenta": BrightMagenta,
+	"bright-cyan":    BrightCyan,
+	"bright-white":   BrightWhite,
+}
+
+// ParseColor converts a colour name such as "red" or "bright-blue" to a Color
+func ParseColor(name string) (Color, error) {
+	if c, ok := colorNames[strings.ToLower(name)]; ok {
+		return c, nil
+	}
+	return 0, fmt.Errorf("unknown colour %q", name)
+}
+
+// Paint wraps s in the escape sequences that draw it in c
+func (c Color) Paint(s string) string {
+	return c.escape() + s + reset
+}
+
+// Logo is a piece of ASCII art. Lines may switch colour with ${c1} to ${c6},
+// which select entries of Colors; the first colour is also used for labels.
+type Logo struct {
+	Name   string
+	Lines  []string
+	Colors []Color
+}
+
+// Field is one line of the summary printed next to the logo
+type Field struct {
+	Label string
+	Value string
+}
+
+//go:embed logos/*.txt
+var logoFiles embed.FS
+
+// palettes lists the colours of every built-in logo
+var palettes = map[string][]Color{
+	"alpine":     {Blue, White},
+	"arch":       {Cyan},
+	"centos":     {Yellow, Green, Blue, Magenta},
+	"debian":     {Red},
+	"elementary": {White},
+	"fedora":     {Blue, White},
+	"gentoo":     {Magenta, White},
+	"kali":       {Blue},
+	"linux":      {White, BrightBlack, Yellow},
+	"linuxmint":  {Green, White},
+	"manjaro":    {Green},
+	"nixos":      {Blue, Cyan},
+	"opensuse":   {Green},
+	"pop":        {Cyan},
+	"raspbian":   {Green, Red},
+	"rhel":       {Red},
+	"ubuntu":     {Red},
+	"void":       {Green},
+	"windows":    {Red, Green, Blue, Yellow},
+}
+
+// aliases maps os-release IDs to the logo drawn for them
+var aliases = map[string]string{
+	"redhat":              "rhel",
+	"opensuse-leap":       "opensuse",
+	"opensuse-tumbleweed": "opensuse",
+	"suse":                "opensuse",
+	"sles":                "opensuse",
+	"mint":                "linuxmint",
+	"pop_os":              "pop",
+	"raspberrypi":         "raspbian",
+	"nix":                 "nixos",
+}
+
+// Detect picks the built-in logo for an os-release ID, falling back on the
+// distributions listed in ID_LIKE and finally on the generic Linux logo
+func Detect(id string, idLike []string) *Logo {
+	for _, candidate := range append([]string{id}, idLike...) {
+		name := strings.ToLower(strings.TrimSpace(candidate))
+		if alias, ok := aliases[name]; ok {
+			name = alias
+		}
+		if logo, err := builtin(name); err == nil {
+			return logo
+		}
+	}
+
+	logo, _ := builtin("linux")
+	return logo
+}
+
+// builtin loads one of the embedded logos
+func builtin(name string) (*Logo, error) {
+	colors, ok := palettes[name]
+	if !ok {
+		return nil, fmt.Errorf("no built-in logo for %q", name)
+	}
+	data, err := logoFiles.ReadFile("logos/" + name + ".txt")
+	if err != nil {
+		return nil, err
+	}
+	return &Logo{Name: name, Lines: splitLines(string(data)), Colors: colors}, nil
+}
+
+// Load reads custom ASCII art from path. The art is drawn with colors, which
+// is usually the palette of the detected distribution logo.
+func Load(path string, colors []Color) (*Logo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Logo{Name: path, Lines: splitLines(string(data)), Colors: colors}, nil
+}
+
+func splitLines(art string) []string {
+	var lines []string
+	scanner := bufio.NewScanner(strings.NewReader(art))
+	for scanner.Scan() {
+		lines = append(lines, strings.ReplaceAll(scanner.Text(), "\t", "    "))
+	}
+	return lines
+}
+
+// colorPattern matches the ${cN} colour markers used in logo art
+var colorPattern = regexp.MustCompile(`\$\{c([1-6])\}`)
+
+// Render writes the logo with the title and fields to its right. Colours are
+// only written when color is set.
+func Render(w io.Writer, logo *Logo, title string, fields []Field, color bool) error {
+	// Width of the art without colour markers
+	width := 0
+	for _, line := range logo.Lines {
+		width = max(width, utf8.RuneCountInString(colorPattern.ReplaceAllString(line, "")))
+	}
+
+	info := logo.infoLines(title, fields, color)
+	current := logo.color(1)
+
+	var out strings.Builder
+	for i := range max(len(logo.Lines), len(info)) {
+		visible := 0
+		if i < len(logo.Lines) {
+			line := logo.Lines[i]
+			visible = utf8.RuneCountInString(colorPattern.ReplaceAllString(line, ""))
+			if color {
+				// Colours carry over from the previous line like in neofetch
+				out.WriteString(current.escape())
+				line = colorPattern.ReplaceAllStringFunc(line, func(marker string) string {
+					current = logo.color(int(marker[3] - '0'))
+					return current.escape()
+				})
+				line += reset
+			} else {
+				line = colorPattern.ReplaceAllString(line, "")
+			}
+			out.WriteString(line)
+		}
+
+		if i < len(info) {
+			out.WriteString(strings.Repeat(" ", width-visible+3))
+			out.WriteString(info[i])
+		}
+		out.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// color returns the nth colour of the palette, counting from 1
+func (l *Logo) color(n int) Color {
+	if len(l.Colors) == 0 {
+		return White
+	}
+	if n > len(l.Colors) {
+		n = len(l.Colors)
+	}
+	return l.Colors[n-1]
+}
+
+// infoLines formats the title, its underline, the fields and the colour blocks
+func (l *Logo) infoLines(title string, fields []Field, color bool) []string {
+	paint := func(c Color, s string) string {
+		if !color {
+			return s
+		}
+		return c.Paint(s)
+	}
+
+	var lines []string
+	if title != "" {
+		lines = append(lines, paint(l.color(1), title), strings.Repeat("-", utf8.RuneCountInString(title)))
+	}
+	for _, field := range fields {
+		if field.Value == "" {
+			continue
+		}
+		lines = append(lines, paint(l.color(1), field.Label)+": "+field.Value)
+	}
+
+	if color {
+		lines = append(lines, "")
+		for _, background := range []int{40, 100} {
+			var blocks strings.Builder
+			for i := range 8 {
+				fmt.Fprintf(&blocks, "\x1b[%dm   ", background+i)
+			}
+			blocks.WriteString(reset)
+			lines = append(lines, blocks.String())
+		}
+	}
+	return lines
+}
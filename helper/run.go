@@ -0,0 +1,118 @@
+package helper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sync"
+	"time"
+)
+
+// ErrTimeout is matched by errors from collectors that exceeded their deadline
+var ErrTimeout = errors.New("collector timed out")
+
+// RunOptions controls how the registered collectors are run
+type RunOptions struct {
+	Workers  int                      // Maximum number of collectors running at once
+	Timeout  time.Duration            // Deadline for each collector, zero for none
+	Timeouts map[string]time.Duration // Per-collector deadlines overriding Timeout, keyed by collector name
+	Settings map[string]Settings      // Per-collector options, keyed by collector name
+	FS       fs.FS                    // File system collectors read from instead of the running system, see RootFS
+	Runner   Runner                   // Runs the commands of the collectors instead of ExecRunner, e.g. to record or replay them
+}
+
+// Result is the outcome of a single collector run
+type Result struct {
+	Collector Collector
+	Value     any
+	Err       error
+	Duration  time.Duration // Wall time spent in the collector
+}
+
+// RunCollectors runs the collectors on a bounded pool of workers and returns
+// their results in the order the collectors were given. A collector that does
+// not finish before its deadline is abandoned and reported with ErrTimeout.
+func RunCollectors(ctx context.Context, collectors []Collector, opts RunOptions) []Result {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+	workers = min(workers, len(collectors))
+
+	results := make([]Result, len(collectors))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				name := collectors[i].Name()
+				collectorCtx := withRunner(withFS(withSettings(ctx, opts.Settings[name]), opts.FS), opts.Runner)
+				results[i] = runCollector(collectorCtx, collectors[i], opts.timeout(name))
+			}
+		}()
+	}
+
+	for i := range collectors {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+func (o RunOptions) timeout(name string) time.Duration {
+	if timeout, ok := o.Timeouts[name]; ok {
+		return timeout
+	}
+	return o.Timeout
+}
+
+// runCollector runs one collector, giving up on it once its deadline passes
+func runCollector(parent context.Context, collector Collector, timeout time.Duration) Result {
+	ctx := parent
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(parent, timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	done := make(chan Result, 1)
+	go func() {
+		result := Result{Collector: collector}
+		defer func() {
+			if r := recover(); r != nil {
+				result.Err = fmt.Errorf("collector panicked: %v", r)
+			}
+			done <- result
+		}()
+		result.Value, result.Err = collector.Collect(ctx)
+	}()
+
+	var result Result
+	select {
+	case result = <-done:
+		// A collector that gave up because of the deadline still timed out
+		if result.Err != nil && ctx.Err() != nil {
+			result.Err = contextError(parent, timeout)
+		}
+	case <-ctx.Done():
+		result = Result{Collector: collector, Err: contextError(parent, timeout)}
+	}
+	result.Duration = time.Since(start)
+
+	return result
+}
+
+// contextError explains why a collector's context ended
+func contextError(parent context.Context, timeout time.Duration) error {
+	if err := parent.Err(); err != nil {
+		return err
+	}
+	return fmt.Errorf("%w after %s", ErrTimeout, timeout)
+}
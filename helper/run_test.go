@@ -0,0 +1,131 @@
+package helper
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Helper function to make a collector that returns value after sleeping for
+// delay, or ctx.Err() if its context ends first
+func sleepCollector(name string, delay time.Duration, value any) Collector {
+	return NewCollector(name, name, func(ctx context.Context) (any, error) {
+		select {
+		case <-time.After(delay):
+			return value, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	})
+}
+
+func TestRunCollectorsOrder(t *testing.T) {
+	// Later collectors finish first, but results keep the order given
+	collectors := []Collector{
+		sleepCollector("a", 30*time.Millisecond, 1),
+		sleepCollector("b", 20*time.Millisecond, 2),
+		sleepCollector("c", 10*time.Millisecond, 3),
+		sleepCollector("d", 0, 4),
+	}
+	results := RunCollectors(context.Background(), collectors, RunOptions{Workers: 4})
+	if len(results) != len(collectors) {
+		t.Fatalf("got %d results, want %d", len(results), len(collectors))
+	}
+	for i, result := range results {
+		if result.Collector != collectors[i] || result.Value != i+1 || result.Err != nil {
+			t.Errorf("%d: got %s = %v, %v, want %s = %d", i, result.Collector.Name(), result.Value, result.Err, collectors[i].Name(), i+1)
+		}
+	}
+}
+
+func TestRunCollectorsTimeout(t *testing.T) {
+	collectors := []Collector{
+		// Ignores its context and is abandoned
+		NewCollector("stuck", "stuck", func(ctx context.Context) (any, error) {
+			time.Sleep(time.Second)
+			return "late", nil
+		}),
+		// Gives up once its context ends
+		sleepCollector("polite", time.Second, "late"),
+		// Gets a deadline of its own
+		sleepCollector("slow", 50*time.Millisecond, "done"),
+		sleepCollector("fast", 0, "done"),
+	}
+	opts := RunOptions{
+		Workers:  4,
+		Timeout:  10 * time.Millisecond,
+		Timeouts: map[string]time.Duration{"slow": time.Second},
+	}
+
+	start := time.Now()
+	results := RunCollectors(context.Background(), collectors, opts)
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("took %v, the stuck collector was waited for", elapsed)
+	}
+
+	for _, result := range results[:2] {
+		if !errors.Is(result.Err, ErrTimeout) || result.Value != nil {
+			t.Errorf("%s: got %v, %v, want ErrTimeout", result.Collector.Name(), result.Value, result.Err)
+		}
+	}
+	for _, result := range results[2:] {
+		if result.Err != nil || result.Value != "done" {
+			t.Errorf("%s: got %v, %v, want done", result.Collector.Name(), result.Value, result.Err)
+		}
+	}
+}
+
+func TestRunCollectorsPanic(t *testing.T) {
+	collectors := []Collector{
+		NewCollector("broken", "broken", func(ctx context.Context) (any, error) {
+			panic("index out of range")
+		}),
+		sleepCollector("fine", 0, "done"),
+	}
+	results := RunCollectors(context.Background(), collectors, RunOptions{Workers: 1})
+
+	if err := results[0].Err; err == nil || !strings.Contains(err.Error(), "index out of range") {
+		t.Errorf("got %v, want the panic as an error", err)
+	}
+	if results[1].Err != nil || results[1].Value != "done" {
+		t.Errorf("got %v, %v after a panic, want done", results[1].Value, results[1].Err)
+	}
+}
+
+func TestRunCollectorsCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	// Cancelling the caller's context is no timeout of the collector
+	results := RunCollectors(ctx, []Collector{sleepCollector("waiting", time.Second, "late")}, RunOptions{Timeout: time.Second})
+	if err := results[0].Err; !errors.Is(err, context.Canceled) || errors.Is(err, ErrTimeout) {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+}
+
+func TestRunCollectorsWorkers(t *testing.T) {
+	var running, most atomic.Int32
+	var collectors []Collector
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		collectors = append(collectors, NewCollector(name, name, func(ctx context.Context) (any, error) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				m := most.Load()
+				if n <= m || most.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			return nil, nil
+		}))
+	}
+
+	RunCollectors(context.Background(), collectors, RunOptions{Workers: 3})
+	if got := most.Load(); got > 3 || got < 1 {
+		t.Errorf("got %d collectors running at once, want at most 3", got)
+	}
+}
@@ -0,0 +1,212 @@
+package helper
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ErrOtherRoot is matched by errors from commands that were not run because
+// the collectors read from another root, which the commands don't describe
+var ErrOtherRoot = errors.New("not run when reading another root")
+
+// ErrNotRecorded is matched by errors from commands a replay has no recording of
+var ErrNotRecorded = errors.New("no recording of the command")
+
+// Runner runs the external commands of the collectors. Run returns the
+// standard output of the command; failures are reported as *CommandError.
+type Runner interface {
+	Run(ctx context.Context, name string, args ...string) ([]byte, error)
+}
+
+// Capture is what a command printed and how it exited, as stored by a
+// recording Runner
+type Capture struct {
+	Argv     []string `json:"argv"`
+	Stdout   string   `json:"stdout"`
+	Stderr   string   `json:"stderr"`
+	ExitCode int      `json:"exit_code"`         // -1 when the command did not exit by itself
+	Error    string   `json:"error,omitempty"`   // Why the command failed, when it did
+	Missing  bool     `json:"missing,omitempty"` // Whether the executable is not installed
+}
+
+// capture runs a command and records its outcome
+func capture(ctx context.Context, name string, args []string) Capture {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+
+	c := Capture{
+		Argv:     append([]string{name}, args...),
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+		ExitCode: -1,
+	}
+	if cmd.ProcessState != nil {
+		c.ExitCode = cmd.ProcessState.ExitCode()
+	}
+	if err != nil {
+		c.Error = err.Error()
+		c.Missing = errors.Is(err, exec.ErrNotFound)
+	}
+	return c
+}
+
+// result turns a capture back into what Run returns
+func (c Capture) result() ([]byte, error) {
+	stdout := []byte(c.Stdout)
+	if c.Error == "" {
+		return stdout, nil
+	}
+
+	var err error
+	switch {
+	case c.Missing:
+		err = &exec.Error{Name: c.Argv[0], Err: exec.ErrNotFound}
+	case c.ExitCode > 0:
+		err = &ExitError{Code: c.ExitCode, Stderr: c.Stderr}
+	default:
+		err = errors.New(c.Error)
+	}
+	return stdout, NewCommandError(c.Argv[0], c.Argv[1:], err)
+}
+
+// ExitError reports a command that exited with a non-zero status
+type ExitError struct {
+	Code   int
+	Stderr string
+}
+
+func (e *ExitError) Error() string {
+	if stderr := strings.TrimSpace(e.Stderr); stderr != "" {
+		return fmt.Sprintf("exit status %d: %s", e.Code, firstLine(stderr))
+	}
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
+
+// ExecRunner returns the Runner that runs commands on the running system
+func ExecRunner() Runner {
+	return execRunner{}
+}
+
+type execRunner struct{}
+
+func (execRunner) Run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return capture(ctx, name, args).result()
+}
+
+// RecordRunner returns a Runner that runs commands on the running system and
+// stores each of them as a JSON Capture in dir, ready for ReplayRunner
+func RecordRunner(dir string) (Runner, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &recordRunner{dir: dir}, nil
+}
+
+type recordRunner struct {
+	dir string
+	mu  sync.Mutex // Serialises writes of commands run more than once
+}
+
+func (r *recordRunner) Run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	c := capture(ctx, name, args)
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	err = os.WriteFile(filepath.Join(r.dir, CaptureFile(c.Argv)), append(data, '\n'), 0o644)
+	r.mu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("recording %s: %w", name, err)
+	}
+
+	return c.result()
+}
+
+// ReplayRunner returns a Runner that serves the commands recorded in dir by
+// RecordRunner instead of running them. Commands without a recording fail
+// with ErrNotRecorded.
+func ReplayRunner(dir string) Runner {
+	return replayRunner(dir)
+}
+
+type replayRunner string
+
+func (r replayRunner) Run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	argv := append([]string{name}, args...)
+	data, err := os.ReadFile(filepath.Join(string(r), CaptureFile(argv)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, NewCommandError(name, args, fmt.Errorf("%w in %s", ErrNotRecorded, string(r)))
+	}
+	if err != nil {
+		return nil, NewCommandError(name, args, err)
+	}
+
+	var c Capture
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, NewCommandError(name, args, fmt.Errorf("recording: %w", err))
+	}
+	return c.result()
+}
+
+// CaptureFile returns the name of the file a command line is recorded in: the
+// command followed by a hash of the whole command line, e.g. sensors-1a2b3c4d.json
+func CaptureFile(argv []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(argv, "\x00")))
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.' {
+			return r
+		}
+		return '_'
+	}, filepath.Base(argv[0]))
+	return fmt.Sprintf("%s-%x.json", name, sum[:4])
+}
+
+// refuseRunner fails every command, for collectors reading from another root
+type refuseRunner struct{}
+
+func (refuseRunner) Run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return nil, NewCommandError(name, args, ErrOtherRoot)
+}
+
+type runnerKey struct{}
+
+// withRunner returns a context carrying the Runner of the collector run with it
+func withRunner(ctx context.Context, runner Runner) context.Context {
+	if runner == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, runnerKey{}, runner)
+}
+
+// CollectorRunner returns the Runner the collector running with ctx runs
+// commands with: RunOptions.Runner, or else ExecRunner. When the collector
+// reads from another root and no Runner is set, commands fail with
+// ErrOtherRoot since they would describe the running system instead.
+func CollectorRunner(ctx context.Context) Runner {
+	if runner, ok := ctx.Value(runnerKey{}).(Runner); ok {
+		return runner
+	}
+	if !IsLive(CollectorFS(ctx)) {
+		return refuseRunner{}
+	}
+	return ExecRunner()
+}
@@ -0,0 +1,72 @@
+package helper
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	dir := t.TempDir()
+	recorder, err := RecordRunner(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	script := "echo out; echo oops >&2; exit 3"
+	recorded, recordErr := recorder.Run(ctx, "sh", "-c", script)
+	_, missingErr := recorder.Run(ctx, "defetch-no-such-tool", "--version")
+
+	var exitErr *ExitError
+	if !errors.As(recordErr, &exitErr) || exitErr.Code != 3 || exitErr.Stderr != "oops\n" {
+		t.Fatalf("recording: got %v, want exit status 3", recordErr)
+	}
+	if !errors.Is(missingErr, ErrToolMissing) {
+		t.Fatalf("recording a missing tool: got %v", missingErr)
+	}
+
+	// Replays return the same output and errors without running anything
+	replayer := ReplayRunner(dir)
+	replayed, replayErr := replayer.Run(ctx, "sh", "-c", script)
+	if string(replayed) != string(recorded) || replayErr.Error() != recordErr.Error() {
+		t.Errorf("replay: got %q, %v, want %q, %v", replayed, replayErr, recorded, recordErr)
+	}
+	if _, err := replayer.Run(ctx, "defetch-no-such-tool", "--version"); !errors.Is(err, ErrToolMissing) {
+		t.Errorf("replaying a missing tool: got %v", err)
+	}
+	if _, err := replayer.Run(ctx, "sh", "-c", "echo other"); !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("replaying an unknown command: got %v, want ErrNotRecorded", err)
+	}
+}
+
+func TestCaptureFile(t *testing.T) {
+	name := CaptureFile([]string{"/usr/sbin/dmidecode", "-s", "bios-version"})
+	if !strings.HasPrefix(name, "dmidecode-") || filepath.Ext(name) != ".json" {
+		t.Errorf("got %q", name)
+	}
+	// Arguments are part of the hash, so commands with other arguments don't collide
+	if other := CaptureFile([]string{"/usr/sbin/dmidecode", "-s", "bios-vendor"}); other == name {
+		t.Errorf("%q for two command lines", name)
+	}
+}
+
+func TestCollectorRunner(t *testing.T) {
+	ctx := context.Background()
+	if _, ok := CollectorRunner(ctx).(execRunner); !ok {
+		t.Error("commands don't run on the running system by default")
+	}
+
+	// Below another root only an explicit Runner runs commands
+	ctx = withFS(ctx, RootFS(os.TempDir()))
+	if _, err := CollectorRunner(ctx).Run(ctx, "true"); !errors.Is(err, ErrOtherRoot) {
+		t.Errorf("got %v, want ErrOtherRoot", err)
+	}
+	replay := ReplayRunner(t.TempDir())
+	if runner := CollectorRunner(withRunner(ctx, replay)); runner != replay {
+		t.Errorf("got %v, want the replay", runner)
+	}
+}
@@ -0,0 +1,180 @@
+package helper
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// schemaProvider is implemented by types with a custom JSON encoding
+type schemaProvider interface {
+	JSONSchema() map[string]any
+}
+
+// Schema returns the JSON Schema of the document WriteJSON produces for a
+// SysInfo. Empty strings, nil slices and nil maps are written as null, so
+// those fields accept null as well.
+func Schema() map[string]any {
+	defs := make(map[string]any)
+	root := schemaFor(reflect.TypeOf(SysInfo{}), defs)
+
+	schema := map[string]any{
+		"$schema":     "https://json-schema.org/draft/2020-12/schema",
+		"title":       "defetch system information",
+		"description": "System information collected by defetch",
+		"$ref":        root["$ref"],
+		"$defs":       defs,
+	}
+
+	// Pin the version so documents are only validated against their own schema
+	sysInfo := defs["SysInfo"].(map[string]any)
+	sysInfo["properties"].(map[string]any)["schema_version"] = map[string]any{
+		"type":  "integer",
+		"const": SchemaVersion,
+	}
+
+	return schema
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+	providerType = reflect.TypeOf((*schemaProvider)(nil)).Elem()
+)
+
+// schemaFor describes t, adding named struct types to defs
+func schemaFor(t reflect.Type, defs map[string]any) map[string]any {
+	if t.Implements(providerType) {
+		return reflect.Zero(t).Interface().(schemaProvider).JSONSchema()
+	}
+
+	switch t {
+	case timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case durationType:
+		return map[string]any{"type": "integer", "description": "Duration in nanoseconds"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return nullable(schemaFor(t.Elem(), defs))
+	case reflect.String:
+		return map[string]any{"type": []any{"string", "null"}}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{
+			"type":  []any{"array", "null"},
+			"items": schemaFor(t.Elem(), defs),
+		}
+	case reflect.Map:
+		return map[string]any{
+			"type":                 []any{"object", "null"},
+			"additionalProperties": schemaFor(t.Elem(), defs),
+		}
+	case reflect.Interface:
+		return map[string]any{}
+	case reflect.Struct:
+		if _, ok := defs[t.Name()]; !ok {
+			defs[t.Name()] = nil // Reserve the name while the fields are described
+			defs[t.Name()] = structSchema(t, defs)
+		}
+		return map[string]any{"$ref": "#/$defs/" + t.Name()}
+	}
+
+	panic("helper: no JSON Schema for type " + t.String())
+}
+
+// structSchema describes the JSON object encoding/json produces for t
+func structSchema(t reflect.Type, defs map[string]any) map[string]any {
+	properties := make(map[string]any)
+	var required []any
+
+	var addFields func(t reflect.Type)
+	addFields = func(t reflect.Type) {
+		for i := range t.NumField() {
+			field := t.Field(i)
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				addFields(field.Type)
+				continue
+			}
+			if !field.IsExported() {
+				continue
+			}
+
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			properties[name] = schemaFor(field.Type, defs)
+			required = append(required, name)
+		}
+	}
+	addFields(t)
+
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+// nullable extends schema to also accept null
+func nullable(schema map[string]any) map[string]any {
+	if _, ok := schema["$ref"]; ok {
+		return map[string]any{"anyOf": []any{schema, map[string]any{"type": "null"}}}
+	}
+
+	types, ok := schema["type"].([]any)
+	if !ok {
+		types = []any{schema["type"]}
+	}
+	for _, typ := range types {
+		if typ == "null" {
+			return schema
+		}
+	}
+
+	out := make(map[string]any, len(schema))
+	for key, value := range schema {
+		out[key] = value
+	}
+	out["type"] = append(types, "null")
+	return out
+}
+
+// JSONSchema describes the encoding written by SectionError.MarshalJSON
+func (*SectionError) JSONSchema() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"section":      map[string]any{"type": "string"},
+			"collector":    map[string]any{"type": "string"},
+			"message":      map[string]any{"type": "string"},
+			"tool_missing": map[string]any{"type": "boolean"},
+			"timed_out":    map[string]any{"type": "boolean"},
+		},
+		"required":             []any{"section", "collector", "message", "tool_missing", "timed_out"},
+		"additionalProperties": false,
+	}
+}
+
+// MarshalSchema encodes the JSON Schema returned by Schema
+func MarshalSchema() ([]byte, error) {
+	data, err := json.MarshalIndent(Schema(), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
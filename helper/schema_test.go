@@ -0,0 +1,70 @@
+package helper
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "record the schema of a new SchemaVersion in testdata")
+
+// Every published SchemaVersion keeps its schema in testdata/schema. Changing
+// the SysInfo struct tree changes the generated schema, which fails this test
+// until SchemaVersion is bumped and the new schema is recorded with -update.
+func TestSchemaMatchesVersion(t *testing.T) {
+	got, err := MarshalSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join("testdata", "schema", fmt.Sprintf("v%d.json", SchemaVersion))
+	// Published schemas are never rewritten, only new versions are recorded
+	if _, err := os.Stat(path); *update && os.IsNotExist(err) {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("no schema recorded for version %d: run go test ./helper -run TestSchemaMatchesVersion -update", SchemaVersion)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("SysInfo changed without a SchemaVersion bump: increase SchemaVersion and run go test ./helper -run TestSchemaMatchesVersion -update")
+	}
+}
+
+func TestSchemaDescribesDocument(t *testing.T) {
+	var buf bytes.Buffer
+	sysInfo := SysInfo{
+		SchemaVersion: SchemaVersion,
+		Errors: map[string]*SectionError{
+			SectionGPU: {Section: SectionGPU, Collector: "gpu", Err: ErrTimeout},
+		},
+	}
+	if err := WriteJSON(&buf, sysInfo); err != nil {
+		t.Fatal(err)
+	}
+
+	var document map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &document); err != nil {
+		t.Fatal(err)
+	}
+
+	defs := Schema()["$defs"].(map[string]any)
+	properties := defs["SysInfo"].(map[string]any)["properties"].(map[string]any)
+	for key := range document {
+		if _, ok := properties[key]; !ok {
+			t.Errorf("document field %q is missing from the schema", key)
+		}
+	}
+	for key := range properties {
+		if _, ok := document[key]; !ok {
+			t.Errorf("schema property %q is never written", key)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package helper
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Settings holds the options of one collector, e.g. from the [collectors.<name>]
+// table of the config file. Values keep the types the config decoder produced.
+type Settings map[string]any
+
+type settingsKey struct{}
+
+// withSettings returns a context carrying the settings of the collector run with it
+func withSettings(ctx context.Context, settings Settings) context.Context {
+	return context.WithValue(ctx, settingsKey{}, settings)
+}
+
+// CollectorSettings returns the settings of the collector running with ctx
+func CollectorSettings(ctx context.Context) Settings {
+	settings, _ := ctx.Value(settingsKey{}).(Settings)
+	return settings
+}
+
+// Int returns the integer setting key, or fallback when it is not set
+func (s Settings) Int(key string, fallback int) int {
+	switch value := s[key].(type) {
+	case int:
+		return value
+	case int64:
+		return int(value)
+	case float64:
+		return int(value)
+	}
+	return fallback
+}
+
+// String returns the string setting key, or fallback when it is not set
+func (s Settings) String(key, fallback string) string {
+	if value, ok := s[key].(string); ok {
+		return value
+	}
+	return fallback
+}
+
+// Bool returns the boolean setting key, or fallback when it is not set
+func (s Settings) Bool(key string, fallback bool) bool {
+	if value, ok := s[key].(bool); ok {
+		return value
+	}
+	return fallback
+}
+
+// Duration returns the setting key, written like "1.5s", or fallback when it
+// is not set
+func (s Settings) Duration(key string, fallback time.Duration) (time.Duration, error) {
+	value, ok := s[key]
+	if !ok {
+		return fallback, nil
+	}
+	text, ok := value.(string)
+	if !ok {
+		return fallback, fmt.Errorf("%s: expected a duration such as \"5s\", got %v", key, value)
+	}
+	duration, err := time.ParseDuration(text)
+	if err != nil {
+		return fallback, fmt.Errorf("%s: %w", key, err)
+	}
+	return duration, nil
+}
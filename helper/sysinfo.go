@@ -1,199 +1,499 @@
 package helper
 
+import "time"
+
 type SysInfo struct {
-	Hostname          string
-	CurrentUser       string
-	OSName            string
-	OSVersion         string
-	OSCodename        string
-	KernelVersion     string
-	Shell             string
-	ShellVersion      string
-	Architecture      string
-	Uptime            string
-	CPU               CPUInfo
-	GPU               GPUInfo
-	Motherboard       MotherboardInfo
-	Memory            MemoryInfo
-	Storage           []StorageInfo
-	Network           []NetworkInfo
-	Battery           BatteryInfo
-	Peripherals       PeripheralInfo
-	Software          SoftwareInfo
-	Performance       PerformanceInfo
-	PackageManagement PackageManagementInfo
-	OtherInfo         OtherInfo
+	SchemaVersion int       `json:"schema_version"` // Version of the JSON document layout
+	CollectedAt   time.Time `json:"collected_at"`   // When collection started
+	HostInfo
+	CPU               CPUInfo                  `json:"cpu"`
+	GPU               []GPUInfo                `json:"gpus"`
+	Motherboard       MotherboardInfo          `json:"motherboard"`
+	Memory            MemoryInfo               `json:"memory"`
+	Storage           []StorageInfo            `json:"storage"`
+	Network           []NetworkInfo            `json:"network"`
+	Routing           RoutingInfo              `json:"routing"`
+	Power             PowerInfo                `json:"power"`
+	Peripherals       PeripheralInfo           `json:"peripherals"`
+	Software          SoftwareInfo             `json:"software"`
+	Performance       PerformanceInfo          `json:"performance"`
+	PackageManagement PackageManagementInfo    `json:"package_management"`
+	OtherInfo         OtherInfo                `json:"other"`
+	Extra             map[string]any           `json:"extra"`      // Values from collectors outside the built-in sections, keyed by collector name
+	Errors            map[string]*SectionError `json:"errors"`     // Collector failures keyed by section
+	Timings           map[string]time.Duration `json:"timings_ns"` // Wall time of each collector, keyed by collector name
+}
+
+type HostInfo struct {
+	Hostname      string   `json:"hostname"`
+	CurrentUser   string   `json:"current_user"`
+	OSName        string   `json:"os_name"`
+	OSVersion     string   `json:"os_version"`
+	OSCodename    string   `json:"os_codename"`
+	OSID          string   `json:"os_id"`      // ID from os-release
+	OSIDLike      []string `json:"os_id_like"` // ID_LIKE from os-release, closest relative first
+	KernelVersion string   `json:"kernel_version"`
+	Shell         string   `json:"shell"`
+	ShellVersion  string   `json:"shell_version"`
+	Architecture  string   `json:"architecture"`
+	Uptime        string   `json:"uptime"`
 }
 
 type CPUInfo struct {
-	ModelName    string
-	Cores        int
-	Threads      int
-	Architecture string
-	Frequency    float64
-	CacheSize    int32
-	Flags        string
+	ModelName    string  `json:"model_name"`
+	Cores        int     `json:"cores"`
+	Threads      int     `json:"threads"`
+	Architecture string  `json:"architecture"`
+	Frequency    float64 `json:"frequency_mhz"`
+	CacheSize    int32   `json:"cache_size_kb"`
+	Flags        string  `json:"flags"`
 }
 
 type GPUInfo struct {
-	ModelName     string
-	DriverVersion string
-	MemorySize    string
+	Card              string `json:"card"`                // DRM card name, e.g. card0
+	PCIAddress        string `json:"pci_address"`         // PCI slot, e.g. 0000:01:00.0
+	ModelName         string `json:"model_name"`          // Device name from pci.ids
+	Vendor            string `json:"vendor"`              // Vendor name from pci.ids
+	VendorID          string `json:"vendor_id"`           // PCI vendor ID, e.g. 10de
+	DeviceID          string `json:"device_id"`           // PCI device ID
+	SubsystemVendorID string `json:"subsystem_vendor_id"` // PCI vendor ID of the board maker
+	SubsystemDeviceID string `json:"subsystem_device_id"` // PCI device ID assigned by the board maker
+	Subsystem         string `json:"subsystem"`           // Board name from pci.ids
+	Driver            string `json:"driver"`              // Kernel driver bound to the device
+	DriverVersion     string `json:"driver_version"`      // Version of the driver module, if it reports one
+	MemorySize        Bytes  `json:"memory_size"`         // Dedicated video memory, where the driver reports it
+	Primary           bool   `json:"primary"`             // Whether this is the GPU the system booted on
 }
 
 type MotherboardInfo struct {
-	Manufacturer string
-	Model        string
-	BIOSVersion  string
-	SerialNumber string
+	Manufacturer string `json:"manufacturer"`
+	Model        string `json:"model"`
+	BIOSVersion  string `json:"bios_version"`
+	SerialNumber string `json:"serial_number"`
 }
 
 type MemoryInfo struct {
-	TotalSize string
-	UsedSize  string
-	FreeSize  string
-	Slots     []MemorySlotInfo
+	TotalSize    Bytes            `json:"total_size"`
+	UsedSize     Bytes            `json:"used_size"`
+	FreeSize     Bytes            `json:"free_size"`
+	Available    Bytes            `json:"available"`    // Estimate of memory available without swapping
+	Buffers      Bytes            `json:"buffers"`      // Block device buffers
+	Cached       Bytes            `json:"cached"`       // Page cache
+	Shared       Bytes            `json:"shared"`       // Shared memory and tmpfs (Shmem)
+	SReclaimable Bytes            `json:"sreclaimable"` // Reclaimable slab memory
+	Dirty        Bytes            `json:"dirty"`        // Memory waiting to be written back to disk
+	Swap         SwapInfo         `json:"swap"`         // Swap space
+	HugePages    HugePagesInfo    `json:"huge_pages"`   // Huge page pool
+	Zram         []ZramDeviceInfo `json:"zram_devices"` // Compressed RAM block devices
+	Slots        []MemorySlotInfo `json:"slots"`
+}
+
+type SwapInfo struct {
+	Total  Bytes `json:"total"`
+	Used   Bytes `json:"used"`
+	Free   Bytes `json:"free"`
+	Cached Bytes `json:"cached"` // Swapped out memory that is also still in RAM
+}
+
+type HugePagesInfo struct {
+	Total    int   `json:"total"`     // Pages in the pool
+	Free     int   `json:"free"`      // Pages not yet allocated
+	Reserved int   `json:"reserved"`  // Pages promised to mappings but not yet allocated
+	Surplus  int   `json:"surplus"`   // Pages beyond the configured pool size
+	PageSize Bytes `json:"page_size"` // Size of one default huge page
+	Size     Bytes `json:"size"`      // Memory used by huge pages of all sizes
+}
+
+type ZramDeviceInfo struct {
+	Device           string  `json:"device"`            // Device name, e.g. zram0
+	Algorithm        string  `json:"algorithm"`         // Compression algorithm
+	DiskSize         Bytes   `json:"disk_size"`         // Size of the device
+	OriginalSize     Bytes   `json:"original_size"`     // Uncompressed size of the stored data
+	CompressedSize   Bytes   `json:"compressed_size"`   // Compressed size of the stored data
+	MemoryUsed       Bytes   `json:"memory_used"`       // Memory used including allocator overhead
+	CompressionRatio float64 `json:"compression_ratio"` // Original size divided by compressed size, 0 when empty
 }
 
 type MemorySlotInfo struct {
-	Size       string
-	FormFactor string
-	Type       string
-	Speed      string
+	Size       string `json:"size"`
+	FormFactor string `json:"form_factor"`
+	Type       string `json:"type"`
+	Speed      string `json:"speed"`
 }
 
 type StorageInfo struct {
-	Device     string
-	Model      string
-	Capacity   string
-	Used       string
-	Available  string
-	FileSystem string
-	MountPoint string
-	ReadSpeed  string
-	WriteSpeed string
+	Device     string            `json:"device"`     // Kernel name of the disk, e.g. nvme0n1
+	Model      string            `json:"model"`      // Model the disk reports
+	Vendor     string            `json:"vendor"`     // Vendor the disk reports, mostly SCSI and USB disks
+	Serial     string            `json:"serial"`     // Serial number, where sysfs has it
+	Capacity   Bytes             `json:"capacity"`   // Size of the disk
+	Rotational bool              `json:"rotational"` // Whether the disk spins
+	Removable  bool              `json:"removable"`  // Whether the medium can be removed, e.g. USB sticks and card readers
+	Used       Bytes             `json:"used"`       // Space used on the file systems mounted from the disk
+	Available  Bytes             `json:"available"`  // Space available on the file systems mounted from the disk
+	Mounts     []MountInfo       `json:"mounts"`     // Mounts of a file system on the whole disk, without a partition table
+	Partitions []PartitionInfo   `json:"partitions"` // Partitions in the order of their numbers
+	IO         DiskIO            `json:"io"`         // Activity while sampled and since boot
+	Benchmark  *DiskBenchmark    `json:"benchmark"`  // Results of defetch bench disk, nil unless run on a mount of the disk
+	Health     *DiskHealth       `json:"health"`     // SMART or NVMe health, nil if the disk could not be asked
+	Holders    []BlockDeviceInfo `json:"holders"`    // Devices stacked on the whole disk, such as an LVM physical volume
+}
+
+// AllMounts returns the mounts of the disk, of its partitions and of the
+// devices stacked on them
+func (s StorageInfo) AllMounts() []MountInfo {
+	mounts := append([]MountInfo(nil), s.Mounts...)
+	var walk func(devices []BlockDeviceInfo)
+	walk = func(devices []BlockDeviceInfo) {
+		for _, device := range devices {
+			mounts = append(mounts, device.Mounts...)
+			walk(device.Holders)
+		}
+	}
+	walk(s.Holders)
+	for _, partition := range s.Partitions {
+		mounts = append(mounts, partition.Mounts...)
+		walk(partition.Holders)
+	}
+	return mounts
+}
+
+type DiskHealth struct {
+	Source             string   `json:"source"`              // How the figures were read: nvme, ata or smartctl
+	Temperature        *float64 `json:"temperature_celsius"` // Current temperature
+	PowerOnHours       *uint64  `json:"power_on_hours"`      // Time spent powered on
+	PowerCycles        *uint64  `json:"power_cycles"`        // Times the disk was powered on
+	MediaErrors        *uint64  `json:"media_errors"`        // Unrecovered data errors, ATA attribute 187 on SATA disks
+	PercentageUsed     *int     `json:"percentage_used"`     // Share of the rated endurance used, NVMe only and above 100 once exceeded
+	ReallocatedSectors *uint64  `json:"reallocated_sectors"` // Sectors remapped to spares, ATA only
+}
+
+type DiskIO struct {
+	ReadSpeed      Bytes         `json:"read_speed"`          // Bytes read per second
+	WriteSpeed     Bytes         `json:"write_speed"`         // Bytes written per second
+	ReadIOPS       float64       `json:"read_iops"`           // Read requests completed per second
+	WriteIOPS      float64       `json:"write_iops"`          // Write requests completed per second
+	ReadLatency    time.Duration `json:"read_latency_ns"`     // Average time of a read request, queueing included
+	WriteLatency   time.Duration `json:"write_latency_ns"`    // Average time of a write request, queueing included
+	QueueDepth     float64       `json:"queue_depth"`         // Average number of requests in flight
+	Utilization    float64       `json:"utilization_percent"` // Share of the time the disk had requests in flight
+	SampleInterval time.Duration `json:"sample_interval_ns"`  // Time between the two samples the figures above were computed from
+	ReadBytes      Bytes         `json:"read_bytes"`          // Bytes read since boot
+	WrittenBytes   Bytes         `json:"written_bytes"`       // Bytes written since boot
+	Reads          uint64        `json:"reads"`               // Read requests completed since boot
+	Writes         uint64        `json:"writes"`              // Write requests completed since boot
+	BusyTime       time.Duration `json:"busy_time_ns"`        // Time spent with requests in flight since boot
+}
+
+type DiskBenchmark struct {
+	Path            string          `json:"path"`             // Directory the test file was written to
+	MountPoint      string          `json:"mount_point"`      // Mount holding the directory
+	FileSize        Bytes           `json:"file_size"`        // Size of the test file
+	Duration        time.Duration   `json:"duration_ns"`      // Time limit of each test
+	SequentialRead  BenchmarkResult `json:"sequential_read"`  // Reads of 1 MiB in file order
+	SequentialWrite BenchmarkResult `json:"sequential_write"` // Writes of 1 MiB in file order
+	RandomRead      BenchmarkResult `json:"random_read"`      // Reads of 4 KiB at random offsets
+	RandomWrite     BenchmarkResult `json:"random_write"`     // Writes of 4 KiB at random offsets
+}
+
+type BenchmarkResult struct {
+	Speed    Bytes         `json:"speed"`      // Bytes transferred per second
+	IOPS     float64       `json:"iops"`       // Requests completed per second, one at a time
+	Latency  time.Duration `json:"latency_ns"` // Average time of a request
+	Requests uint64        `json:"requests"`   // Requests completed during the test
+}
+
+type MountInfo struct {
+	MountPoint string   `json:"mount_point"` // Where the file system is mounted
+	FileSystem string   `json:"file_system"` // File system type, e.g. ext4
+	Source     string   `json:"source"`      // What was mounted, e.g. /dev/nvme0n1p2
+	Root       string   `json:"root"`        // Directory of the file system that is mounted, other than / for bind mounts and subvolumes
+	Options    []string `json:"options"`     // Mount and file system options, e.g. rw and relatime
+	Size       Bytes    `json:"size"`        // Size of the file system
+	Used       Bytes    `json:"used"`        // Used space
+	Available  Bytes    `json:"available"`   // Space available to unprivileged users
 }
 
 type NetworkInfo struct {
-	InterfaceName  string
-	IPAddress      string
-	MACAddress     string
-	Speed          string
-	Active         bool
-	DefaultGateway string
+	InterfaceName  string         `json:"interface_name"`  // Interface name, e.g. eth0
+	Index          int            `json:"index"`           // Kernel interface index
+	Type           string         `json:"type"`            // ethernet, wifi, loopback, bridge, bond, veth, tun, vlan, ...
+	Driver         string         `json:"driver"`          // Kernel driver of the device, empty for virtual interfaces
+	MACAddress     string         `json:"mac_address"`     // Hardware address
+	MTU            int            `json:"mtu"`             // Maximum transmission unit in bytes
+	OperState      string         `json:"operstate"`       // RFC 2863 state: up, down, dormant, lowerlayerdown, unknown, ...
+	Carrier        bool           `json:"carrier"`         // Whether a link is detected
+	Active         bool           `json:"active"`          // Whether the interface is up and has a link
+	SpeedMbps      int            `json:"speed_mbps"`      // Negotiated link speed in Mbit/s, 0 when unknown
+	Duplex         string         `json:"duplex"`          // full, half or unknown
+	Addresses      []AddressInfo  `json:"addresses"`       // IPv4 and IPv6 addresses
+	DefaultGateway string         `json:"default_gateway"` // IPv4 gateway of the default route through the interface
+	Statistics     InterfaceStats `json:"statistics"`      // Counters since the interface was created
+	Wireless       *WirelessInfo  `json:"wireless"`        // Wi-Fi link, nil for other interfaces
+}
+
+type AddressInfo struct {
+	Address      string `json:"address"`       // IP address
+	PrefixLength int    `json:"prefix_length"` // Length of the network prefix, e.g. 24
+	Family       string `json:"family"`        // ipv4 or ipv6
+	Scope        string `json:"scope"`         // global, site, link or host
+}
+
+type InterfaceStats struct {
+	RXBytes   Bytes  `json:"rx_bytes"`   // Bytes received
+	TXBytes   Bytes  `json:"tx_bytes"`   // Bytes sent
+	RXPackets uint64 `json:"rx_packets"` // Packets received
+	TXPackets uint64 `json:"tx_packets"` // Packets sent
+	RXErrors  uint64 `json:"rx_errors"`  // Receive errors
+	TXErrors  uint64 `json:"tx_errors"`  // Transmit errors
+	RXDropped uint64 `json:"rx_dropped"` // Received packets dropped
+	TXDropped uint64 `json:"tx_dropped"` // Packets dropped before sending
+}
+
+type RoutingInfo struct {
+	Routes          []RouteInfo   `json:"routes"`           // IPv4 and IPv6 routes of the main routing table
+	Rules           []RuleInfo    `json:"rules"`            // Policy routing rules in the order they are evaluated
+	DefaultGateways []GatewayInfo `json:"default_gateways"` // Default routes through a gateway, preferred first within each family
+	DNS             DNSInfo       `json:"dns"`              // Resolver configuration
+}
+
+type RouteInfo struct {
+	Family      string `json:"family"`      // ipv4 or ipv6
+	Destination string `json:"destination"` // Destination network, e.g. 192.168.1.0/24 or ::/0
+	Gateway     string `json:"gateway"`     // Next hop, empty for directly connected networks
+	Interface   string `json:"interface"`   // Outgoing interface
+	Metric      int    `json:"metric"`      // Route priority, lower is preferred
+}
+
+type RuleInfo struct {
+	Family          string `json:"family"`           // ipv4 or ipv6
+	Priority        int    `json:"priority"`         // Order of evaluation, lower first
+	Source          string `json:"source"`           // Source network matched, empty for any
+	Destination     string `json:"destination"`      // Destination network matched, empty for any
+	InputInterface  string `json:"input_interface"`  // Incoming interface matched, empty for any
+	OutputInterface string `json:"output_interface"` // Outgoing interface matched, empty for any
+	FWMark          uint32 `json:"fwmark"`           // Firewall mark matched, 0 for any
+	Action          string `json:"action"`           // lookup, goto, nop, blackhole, unreachable or prohibit
+	Table           string `json:"table"`            // Routing table looked up, by name where it has one
+}
+
+type GatewayInfo struct {
+	Family    string `json:"family"`    // ipv4 or ipv6
+	Gateway   string `json:"gateway"`   // Address of the gateway
+	Interface string `json:"interface"` // Interface the gateway is reached through
+	Metric    int    `json:"metric"`    // Route priority, lower is preferred
+}
+
+type DNSInfo struct {
+	Resolver      string   `json:"resolver"`       // systemd-resolved when resolv.conf points to its stub, resolv.conf otherwise
+	Nameservers   []string `json:"nameservers"`    // Servers queried, for systemd-resolved those it forwards to
+	SearchDomains []string `json:"search_domains"` // Domains appended to short names
+	Options       []string `json:"options"`        // Resolver options, e.g. edns0
+}
+
+type WirelessInfo struct {
+	SSID             string  `json:"ssid"`              // Network the interface is connected to, empty when disconnected
+	BSSID            string  `json:"bssid"`             // Hardware address of the access point
+	FrequencyMHz     int     `json:"frequency_mhz"`     // Centre frequency of the primary channel
+	Band             string  `json:"band"`              // 2.4 GHz, 5 GHz, 6 GHz or 60 GHz
+	Channel          int     `json:"channel"`           // Channel number within the band
+	ChannelWidthMHz  int     `json:"channel_width_mhz"` // Width of the channel, e.g. 20 or 80
+	SignalDBm        int     `json:"signal_dbm"`        // Signal strength of the access point, 0 when unknown
+	TXBitrateMbps    float64 `json:"tx_bitrate_mbps"`   // Rate of the last frame sent
+	RXBitrateMbps    float64 `json:"rx_bitrate_mbps"`   // Rate of the last frame received
+	RegulatoryDomain string  `json:"regulatory_domain"` // Country whose rules the radio follows, 00 for the world domain
+}
+
+type PowerInfo struct {
+	OnAC      bool          `json:"on_ac"`     // Whether any mains or USB adapter is supplying power
+	Adapters  []AdapterInfo `json:"adapters"`  // Mains and USB power adapters
+	Batteries []BatteryInfo `json:"batteries"` // System batteries and those of connected devices
+}
+
+type AdapterInfo struct {
+	Name   string `json:"name"`   // Power supply name, e.g. AC or ADP1
+	Type   string `json:"type"`   // Mains, USB, ...
+	Online bool   `json:"online"` // Whether the adapter is supplying power
 }
 
 type BatteryInfo struct {
-	Status       string // Charging or discharging status
-	Capacity     string // Battery capacity
-	Percentage   string // Battery percentage remaining
-	Manufacturer string // Battery manufacturer
-	Model        string // Battery model
+	Name             string        `json:"name"`                  // Power supply name, e.g. BAT0
+	Scope            string        `json:"scope"`                 // "Device" for batteries of peripherals, empty for system batteries
+	Status           string        `json:"status"`                // Charging, Discharging, Full, Not charging or Unknown
+	Percentage       float64       `json:"percentage"`            // Charge remaining
+	Manufacturer     string        `json:"manufacturer"`          // Battery manufacturer
+	Model            string        `json:"model"`                 // Battery model
+	Serial           string        `json:"serial"`                // Battery serial number
+	Technology       string        `json:"technology"`            // Cell chemistry, e.g. Li-ion
+	CycleCount       int           `json:"cycle_count"`           // Charge cycles, 0 when not reported
+	EnergyNow        float64       `json:"energy_now_wh"`         // Energy remaining in Wh
+	EnergyFull       float64       `json:"energy_full_wh"`        // Energy when fully charged in Wh
+	EnergyFullDesign float64       `json:"energy_full_design_wh"` // Energy the battery was designed to hold in Wh
+	Wear             float64       `json:"wear_percent"`          // Capacity lost compared to the design capacity
+	Voltage          float64       `json:"voltage_v"`             // Current voltage
+	PowerDraw        float64       `json:"power_draw_w"`          // Rate of charge or discharge in W
+	TimeToEmpty      time.Duration `json:"time_to_empty_ns"`      // Estimated time until empty while discharging
+	TimeToFull       time.Duration `json:"time_to_full_ns"`       // Estimated time until full while charging
 }
 
 type PeripheralInfo struct {
-	ConnectedDevices []string        // List of connected devices (e.g., mouse, keyboard, monitors)
-	USBDevices       []USBDeviceInfo // List of USB devices
-	AudioDevices     []string        // List of audio devices
-	PrinterDetails   []string        // List of printer details
+	ConnectedDevices []string        `json:"connected_devices"` // List of connected devices (e.g., mouse, keyboard, monitors)
+	USBDevices       []USBDeviceInfo `json:"usb_devices"`       // List of USB devices
+	AudioDevices     []string        `json:"audio_devices"`     // List of audio devices
+	PrinterDetails   []string        `json:"printer_details"`   // List of printer details
 }
 
 type USBDeviceInfo struct {
-	Name      string // Name of the USB device
-	Vendor    string // Vendor name
-	ProductID string // Product ID
-	VendorID  string // Vendor ID
+	Name         string   `json:"name"`          // Name of the USB device
+	Vendor       string   `json:"vendor"`        // Vendor name
+	ProductID    string   `json:"product_id"`    // Product ID
+	VendorID     string   `json:"vendor_id"`     // Vendor ID
+	Path         string   `json:"path"`          // Sysfs name, "usb<bus>" for root hubs or "<bus>-<port>.<port>..." for devices
+	Parent       string   `json:"parent"`        // Path of the hub the device is plugged into, empty for root hubs
+	Bus          int      `json:"bus"`           // Bus number
+	Port         string   `json:"port"`          // Port path from the root hub, e.g. 1.2, empty for root hubs
+	DeviceNumber int      `json:"device_number"` // Address of the device on its bus
+	Version      string   `json:"version"`       // USB version the device supports, e.g. 2.00
+	SpeedMbps    float64  `json:"speed_mbps"`    // Negotiated speed in Mbit/s
+	ClassID      string   `json:"class_id"`      // Device class, or the class of its first interface when defined per interface
+	Class        string   `json:"class"`         // Name of the class from usb.ids
+	Serial       string   `json:"serial"`        // Serial number, if the device reports one
+	MaxPowerMA   int      `json:"max_power_ma"`  // Maximum power draw in the active configuration, in mA
+	Drivers      []string `json:"drivers"`       // Drivers bound to the interfaces of the device
 }
 
 type SoftwareInfo struct {
-	OSDetails          string           // Operating System details (Distro name or Windows edition)
-	DesktopEnvironment string           // Desktop Environment name and version
-	WindowManager      string           // Window Manager name and version
-	WMTheme            string           // Window Manager theme
-	GTKTheme           string           // GTK theme
-	IconsTheme         string           // Icons theme
-	Font               string           // Font used in the system
-	Browser            []BrowserInfo    // List of installed browsers and their versions
-	RunningProcesses   []ProcessInfo    // Information on running processes
-	StartupPrograms    []StartupProgram // List of programs that run on startup
+	OSDetails          string           `json:"os_details"`          // Operating System details (Distro name or Windows edition)
+	DesktopEnvironment string           `json:"desktop_environment"` // Desktop Environment name and version
+	WindowManager      string           `json:"window_manager"`      // Window Manager name and version
+	WMTheme            string           `json:"wm_theme"`            // Window Manager theme
+	GTKTheme           string           `json:"gtk_theme"`           // GTK theme
+	IconsTheme         string           `json:"icons_theme"`         // Icons theme
+	Font               string           `json:"font"`                // Font used in the system
+	Browser            []BrowserInfo    `json:"browsers"`            // List of installed browsers and their versions
+	RunningProcesses   []ProcessInfo    `json:"running_processes"`   // Information on running processes
+	StartupPrograms    []StartupProgram `json:"startup_programs"`    // List of programs that run on startup
 }
 
 type BrowserInfo struct {
-	Name    string // Browser name
-	Version string // Browser version
+	Name    string `json:"name"`    // Browser name
+	Version string `json:"version"` // Browser version
 }
 
 type ProcessInfo struct {
-	PID         int     // Process ID
-	Name        string  // Process name
-	CPUUsage    float64 // CPU usage percentage
-	MemoryUsage float64 // Memory usage percentage
+	PID         int     `json:"pid"`          // Process ID
+	Name        string  `json:"name"`         // Process name
+	CPUUsage    float64 `json:"cpu_usage"`    // CPU usage percentage
+	MemoryUsage float64 `json:"memory_usage"` // Memory usage percentage
 }
 
 type StartupProgram struct {
-	Name    string // Program name
-	Command string // Command or path to the executable
+	Name    string `json:"name"`    // Program name
+	Command string `json:"command"` // Command or path to the executable
 }
 
 type PerformanceInfo struct {
-	CPUUsage          float64          // Overall CPU usage percentage
-	PerCoreUsage      []float64        // CPU usage percentage per core
-	MemoryUsage       MemoryUsageInfo  // Memory usage information
-	PerAppMemoryUsage []AppMemoryUsage // Memory usage per application
+	CPUUsage          float64          `json:"cpu_usage"`            // Overall CPU usage percentage
+	PerCoreUsage      []float64        `json:"per_core_usage"`       // CPU usage percentage per core
+	CPUTimes          CPUTimes         `json:"cpu_times"`            // Breakdown of overall CPU time
+	PerCoreTimes      []CPUTimes       `json:"per_core_times"`       // Breakdown of CPU time per core
+	SampleInterval    time.Duration    `json:"sample_interval_ns"`   // Time between the two samples the usage was computed from
+	MemoryUsage       MemoryUsageInfo  `json:"memory_usage"`         // Memory usage information
+	PerAppMemoryUsage []AppMemoryUsage `json:"per_app_memory_usage"` // Memory usage per application
+}
+
+type CPUTimes struct {
+	User    float64 `json:"user"`    // Percentage of time in user mode, including guests
+	Nice    float64 `json:"nice"`    // Percentage of time in user mode at low priority
+	System  float64 `json:"system"`  // Percentage of time in kernel mode
+	IOWait  float64 `json:"iowait"`  // Percentage of idle time waiting for I/O
+	IRQ     float64 `json:"irq"`     // Percentage of time servicing hardware interrupts
+	SoftIRQ float64 `json:"softirq"` // Percentage of time servicing software interrupts
+	Steal   float64 `json:"steal"`   // Percentage of time taken by the hypervisor for other guests
+	Idle    float64 `json:"idle"`    // Percentage of idle time
 }
 
 type MemoryUsageInfo struct {
-	TotalUsed string // Total used memory
-	Free      string // Free memory
-	Total     string // Total memory
+	TotalUsed Bytes `json:"total_used"` // Total used memory
+	Free      Bytes `json:"free"`       // Free memory
+	Total     Bytes `json:"total"`      // Total memory
 }
 
 type AppMemoryUsage struct {
-	Name        string  // Application name
-	PID         int     // Process ID
-	MemoryUsage float64 // Memory usage percentage
+	Name        string  `json:"name"`         // Application name
+	PID         int     `json:"pid"`          // Process ID
+	MemoryUsage float64 `json:"memory_usage"` // Memory usage percentage
 }
 
 type PackageManagementInfo struct {
-	PackageCount              int           // Number of installed packages (Linux) or programs (Windows)
-	AvailableUpdates          int           // Number of available updates (Linux)
-	PackageManagers           []string      // List of used package managers (Linux)
-	RecentlyInstalledPackages []PackageInfo // List of recently installed packages (Linux)
+	PackageCount              int           `json:"package_count"`               // Number of installed packages (Linux) or programs (Windows)
+	AvailableUpdates          int           `json:"available_updates"`           // Number of available updates (Linux)
+	PackageManagers           []string      `json:"package_managers"`            // List of used package managers (Linux)
+	RecentlyInstalledPackages []PackageInfo `json:"recently_installed_packages"` // List of recently installed packages (Linux)
 }
 
 type PackageInfo struct {
-	Name          string // Package name
-	Version       string // Package version
-	InstalledDate string // Installation date
+	Name          string `json:"name"`           // Package name
+	Version       string `json:"version"`        // Package version
+	InstalledDate string `json:"installed_date"` // Installation date
 }
 
 type OtherInfo struct {
-	PublicIP         string          // Public IP address
-	Timezone         string          // Timezone
-	Locale           string          // Locale
-	Temperature      TemperatureInfo // Temperature sensors information
-	SystemLanguage   string          // System language
-	ScreenResolution []ScreenInfo    // Screen resolution and monitor details
-	DiskPartitions   []PartitionInfo // Disk partitions
+	PublicIP         string          `json:"public_ip"`         // Public IP address
+	Timezone         string          `json:"timezone"`          // Timezone
+	Locale           string          `json:"locale"`            // Locale
+	Temperature      TemperatureInfo `json:"temperature"`       // Temperature sensors information
+	SystemLanguage   string          `json:"system_language"`   // System language
+	ScreenResolution []ScreenInfo    `json:"screen_resolution"` // Screen resolution and monitor details
 }
 
 type TemperatureInfo struct {
-	CPU         float64 // CPU temperature
-	GPU         float64 // GPU temperature
-	Motherboard float64 // Motherboard temperature
+	CPU         *float64 `json:"cpu_celsius"`         // CPU temperature, nil if no sensor was found
+	GPU         *float64 `json:"gpu_celsius"`         // GPU temperature, nil if no sensor was found
+	Motherboard *float64 `json:"motherboard_celsius"` // Motherboard temperature, nil if no sensor was found
 }
 
 type ScreenInfo struct {
-	Model       string // Monitor model
-	Resolution  string // Resolution
-	RefreshRate int    // Refresh rate in Hz
+	Model       string `json:"model"`        // Monitor model
+	Resolution  string `json:"resolution"`   // Resolution
+	RefreshRate int    `json:"refresh_rate"` // Refresh rate in Hz
 }
 
 type PartitionInfo struct {
-	Device     string // Partition device name
-	MountPoint string // Mount point
-	Filesystem string // Filesystem type
-	Size       string // Partition size
-	Used       string // Used space
-	Available  string // Available space
+	Device     string            `json:"device"`      // Partition device name
+	Number     int               `json:"number"`      // Number in the partition table
+	MountPoint string            `json:"mount_point"` // Mount point, the first of the mounts
+	Filesystem string            `json:"filesystem"`  // Filesystem type of the mounts, swap for active swap partitions
+	Size       Bytes             `json:"size"`        // Partition size
+	Used       Bytes             `json:"used"`        // Used space
+	Available  Bytes             `json:"available"`   // Available space
+	Mounts     []MountInfo       `json:"mounts"`      // Every mount of the file system, including bind mounts
+	Holders    []BlockDeviceInfo `json:"holders"`     // Devices stacked on the partition, such as RAID arrays
+}
+
+type BlockDeviceInfo struct {
+	Device        string            `json:"device"`         // Kernel name, e.g. md0 or dm-1
+	Name          string            `json:"name"`           // Device-mapper name, as found in /dev/mapper
+	Type          string            `json:"type"`           // raid, crypt, lvm, or dm for other device-mapper targets
+	Size          Bytes             `json:"size"`           // Size of the device
+	RAID          *RAIDInfo         `json:"raid"`           // md array, nil for other types
+	Encryption    string            `json:"encryption"`     // dm-crypt format, e.g. LUKS2 or PLAIN
+	VolumeGroup   string            `json:"volume_group"`   // LVM volume group of a logical volume
+	LogicalVolume string            `json:"logical_volume"` // LVM logical volume name
+	Filesystem    string            `json:"filesystem"`     // Filesystem type of the mounts, swap for active swap
+	Mounts        []MountInfo       `json:"mounts"`         // Mounts of a file system on the device
+	Holders       []BlockDeviceInfo `json:"holders"`        // Devices stacked on this one, e.g. the logical volumes of a volume group
+}
+
+type RAIDInfo struct {
+	Level         string   `json:"level"`                 // RAID level, e.g. raid1
+	State         string   `json:"state"`                 // active, inactive, or active (auto-read-only) before the first write
+	Devices       int      `json:"devices"`               // Devices the array is made of
+	ActiveDevices int      `json:"active_devices"`        // Devices in sync
+	Degraded      bool     `json:"degraded"`              // Whether devices are missing or out of sync
+	Members       []string `json:"members"`               // Member devices in the order of their roles
+	Failed        []string `json:"failed"`                // Members marked faulty
+	Spares        []string `json:"spares"`                // Spare members
+	SyncAction    string   `json:"sync_action"`           // resync, recovery, check, repair or reshape in progress, empty when idle
+	SyncProgress  float64  `json:"sync_progress_percent"` // Progress of the sync action
 }
@@ -4,128 +4,217 @@
 package windows
 
 import (
+	"context"
 	"defetch/helper"
+	"errors"
 	"fmt"
 	"os"
 	"os/user"
 	"syscall"
+	"time"
 	"unsafe"
 
 	"github.com/StackExchange/wmi"
 	"golang.org/x/sys/windows"
 )
 
-// GetWindowsInfo retrieves all system information on a Windows machine
-func GetWindowsInfo() helper.SysInfo {
-	// Hostname
-	hostname, _ := os.Hostname()
+// GetWindowsInfo runs the collectors and assembles their results
+func GetWindowsInfo(ctx context.Context, collectors []helper.Collector, opts helper.RunOptions) SysInfo {
+	sysInfo := SysInfo{
+		SchemaVersion: helper.SchemaVersion,
+		CollectedAt:   time.Now().UTC(),
+		Timings:       make(map[string]time.Duration),
+	}
+	for _, result := range helper.RunCollectors(ctx, collectors, opts) {
+		sysInfo.Timings[result.Collector.Name()] = result.Duration
+		if result.Err != nil {
+			if sysInfo.Errors == nil {
+				sysInfo.Errors = make(map[string]*helper.SectionError)
+			}
+			helper.AddSectionError(sysInfo.Errors, result.Collector, result.Err)
+		}
+		// Failing collectors may still return partial information
+		if result.Value != nil {
+			setSection(&sysInfo, result.Collector, result.Value)
+		}
+	}
 
-	// Current User
-	currentUser, _ := user.Current()
+	return sysInfo
+}
 
-	// OS Name, Version, Build Number
-	osName, osVersion, osBuildNumber := getOSInfo()
+func init() {
+	register("host", helper.SectionHost, func() (any, error) { return getHostInfo() })
+	register("cpu", helper.SectionCPU, func() (any, error) { return getCPU() })
+	register("gpu", helper.SectionGPU, func() (any, error) { return getGPU() })
+	register("memory", helper.SectionMemory, func() (any, error) { return getMemory() })
+	register("storage", helper.SectionStorage, func() (any, error) { return getDisk() })
+	register("battery", helper.SectionBattery, func() (any, error) { return getBattery() })
+	register("software", helper.SectionSoftware, func() (any, error) { return getSoftware() })
+	register("packages", helper.SectionPackages, func() (any, error) { return getPackageManagement() })
+	register("other", helper.SectionOther, func() (any, error) { return getOtherInfo(), nil })
+}
 
-	// Manufacturer and Model
-	manufacturer, model := getSystemManufacturerAndModel()
+// Register one of the built-in collectors
+func register(name, section string, fn func() (any, error)) {
+	helper.Register(helper.NewCollector(name, section, func(ctx context.Context) (any, error) {
+		return fn()
+	}))
+}
 
-	// Kernel Version
-	kernelVersion := getKernelVersion()
+// Store a collected value in its section of sysInfo
+func setSection(sysInfo *SysInfo, collector helper.Collector, value any) {
+	var ok bool
+	switch collector.Section() {
+	case helper.SectionHost:
+		ok = assign(&sysInfo.HostInfo, value)
+	case helper.SectionCPU:
+		ok = assign(&sysInfo.Hardware.CPU, value)
+	case helper.SectionGPU:
+		ok = assign(&sysInfo.Hardware.GPU, value)
+	case helper.SectionMemory:
+		ok = assign(&sysInfo.Hardware.Memory, value)
+	case helper.SectionStorage:
+		ok = assign(&sysInfo.Hardware.Disk, value)
+	case helper.SectionBattery:
+		ok = assign(&sysInfo.Hardware.Battery, value)
+	case helper.SectionSoftware:
+		ok = assign(&sysInfo.Software, value)
+	case helper.SectionPackages:
+		ok = assign(&sysInfo.PackageManagement, value)
+	case helper.SectionOther:
+		ok = assign(&sysInfo.OtherInfo, value)
+	}
 
-	// System Uptime
-	systemUptime := getSystemUptime()
+	// Anything that doesn't fit a built-in section is kept as is
+	if !ok {
+		if sysInfo.Extra == nil {
+			sysInfo.Extra = make(map[string]any)
+		}
+		sysInfo.Extra[collector.Name()] = value
+	}
+}
 
-	// Installed Packages
-	installedPackages := getInstalledPackagesCount()
+// Assign value to dst if it has the expected type
+func assign[T any](dst *T, value any) bool {
+	v, ok := value.(T)
+	if ok {
+		*dst = v
+	}
+	return ok
+}
 
-	// Shell Info
-	shellName := getShellName()
-	shellVersion := getShellVersion()
+// Get Hostname, Current User, OS, Manufacturer and Model
+func getHostInfo() (HostInfo, error) {
+	hostname, hostnameErr := os.Hostname()
+
+	var username string
+	if currentUser, err := user.Current(); err == nil {
+		username = currentUser.Username
+	}
 
-	// Display Info
-	primaryDisplayResolution := getPrimaryDisplayResolution()
+	osName, osVersion, _, osErr := getOSInfo()
+	manufacturer, model, systemErr := getSystemManufacturerAndModel()
+
+	return HostInfo{
+		Hostname:     hostname,
+		CurrentUser:  username,
+		OSName:       osName,
+		OSVersion:    osVersion,
+		Manufacturer: manufacturer,
+		Model:        model,
+	}, errors.Join(hostnameErr, osErr, systemErr)
+}
+
+// Get CPU section
+func getCPU() (CPUInfo, error) {
+	cpuModel, cpuCores, cpuThreads, cpuSpeed, err := getCPUInfo()
+	return CPUInfo{
+		Model:      cpuModel,
+		Cores:      cpuCores,
+		Threads:    cpuThreads,
+		BaseSpeed:  cpuSpeed.Base,
+		BoostSpeed: cpuSpeed.Boost,
+	}, err
+}
 
-	// Desktop Environment and Window Manager
-	desktopEnvironment := "Windows Explorer"
-	windowManager := "DWM"
-	currentTheme := getCurrentTheme()
+// Get GPU section
+func getGPU() (GPUInfo, error) {
+	gpuModel, gpuDriverVersion, err := getGPUInfo()
+	return GPUInfo{
+		Model:         gpuModel,
+		DriverVersion: gpuDriverVersion,
+	}, err
+}
 
-	// CPU Information
-	cpuModel, cpuCores, cpuThreads, cpuSpeed := getCPUInfo()
+// Get Memory section
+func getMemory() (MemoryInfo, error) {
+	totalRAM, usedRAM, freeRAM, err := getMemoryInfo()
+	return MemoryInfo{
+		Total: helper.Bytes(totalRAM),
+		Used:  helper.Bytes(usedRAM),
+		Free:  helper.Bytes(freeRAM),
+	}, err
+}
 
-	// GPU Information
-	gpuModel, gpuDriverVersion := getGPUInfo()
+// Get Disk section
+func getDisk() (DiskInfo, error) {
+	totalDiskSpace, usedDiskSpace, freeDiskSpace, err := getDiskInfo()
+	return DiskInfo{
+		Total: helper.Bytes(totalDiskSpace),
+		Used:  helper.Bytes(usedDiskSpace),
+		Free:  helper.Bytes(freeDiskSpace),
+	}, err
+}
 
-	// Memory Information
-	totalRAM, usedRAM, freeRAM := getMemoryInfo()
+// Get Battery section
+func getBattery() (BatteryInfo, error) {
+	batteryPercentage, batteryStatus, err := getBatteryInfo()
+	return BatteryInfo{
+		Percentage: batteryPercentage,
+		Status:     batteryStatus,
+	}, err
+}
 
-	// Disk Information
-	totalDiskSpace, usedDiskSpace, freeDiskSpace := getDiskInfo()
+// Get Software section
+func getSoftware() (Software, error) {
+	osName, osVersion, osBuildNumber, err := getOSInfo()
+	var osDetails string
+	if err == nil {
+		osDetails = osName + " " + osVersion + " (Build " + osBuildNumber + ")"
+	}
+	return Software{
+		OSDetails:          osDetails,
+		ShellName:          getShellName(),
+		ShellVersion:       getShellVersion(),
+		DesktopEnvironment: "Windows Explorer",
+		WindowManager:      "DWM",
+	}, err
+}
 
-	// Battery Information
-	batteryPercentage, batteryStatus := getBatteryInfo()
+// Get Package Management section
+func getPackageManagement() (PackageManagement, error) {
+	packageCount, err := getInstalledPackagesCount()
+	return PackageManagement{
+		PackageCount: packageCount,
+	}, err
+}
 
-	return helper.SysInfo{
-		Hostname:    hostname,
-		CurrentUser: currentUser.Username,
-		OSName:      osName,
-		OSVersion:   osVersion,
-		OtherInfo: helper.OtherInfo{
-			KernelVersion:  kernelVersion,
-			Uptime:         systemUptime,
-			PublicIP:       "",
-			Timezone:       "",
-			Locale:         "",
-			SystemLanguage: "",
-			ScreenResolution: []helper.ScreenInfo{
-				{
-					Resolution: primaryDisplayResolution,
-				},
-			},
-			CurrentTheme: currentTheme,
-		},
-		Software: helper.SoftwareInfo{
-			OSDetails:          osName + " " + osVersion + " (Build " + osBuildNumber + ")",
-			DesktopEnvironment: desktopEnvironment,
-			WindowManager:      windowManager,
-			RunningProcesses:   nil,
-			StartupPrograms:    nil,
-		},
-		PackageManagement: helper.PackageManagementInfo{
-			PackageCount: installedPackages,
-		},
-		Hardware: helper.HardwareInfo{
-			CPU: helper.CPUInfo{
-				Model:      cpuModel,
-				Cores:      cpuCores,
-				Threads:    cpuThreads,
-				BaseSpeed:  cpuSpeed.Base,
-				BoostSpeed: cpuSpeed.Boost,
-			},
-			GPU: helper.GPUInfo{
-				Model:         gpuModel,
-				DriverVersion: gpuDriverVersion,
-			},
-			Memory: helper.MemoryInfo{
-				Total: totalRAM,
-				Used:  usedRAM,
-				Free:  freeRAM,
-			},
-			Disk: helper.DiskInfo{
-				Total: totalDiskSpace,
-				Used:  usedDiskSpace,
-				Free:  freeDiskSpace,
-			},
-			Battery: helper.BatteryInfo{
-				Percentage: batteryPercentage,
-				Status:     batteryStatus,
+// Get Other section
+func getOtherInfo() OtherInfo {
+	return OtherInfo{
+		KernelVersion: getKernelVersion(),
+		Uptime:        getSystemUptime(),
+		ScreenResolution: []ScreenResolution{
+			{
+				Resolution: getPrimaryDisplayResolution(),
 			},
 		},
+		CurrentTheme: getCurrentTheme(),
 	}
 }
 
 // Get OS Name, Version, and Build Number
-func getOSInfo() (string, string, string) {
+func getOSInfo() (string, string, string, error) {
 	var osInfo struct {
 		Caption     string
 		Version     string
@@ -134,14 +223,14 @@ func getOSInfo() (string, string, string) {
 
 	err := wmi.Query("SELECT Caption, Version, BuildNumber FROM Win32_OperatingSystem", &osInfo)
 	if err != nil {
-		return "Unknown", "Unknown", "Unknown"
+		return "", "", "", err
 	}
 
-	return osInfo.Caption, osInfo.Version, osInfo.BuildNumber
+	return osInfo.Caption, osInfo.Version, osInfo.BuildNumber, nil
 }
 
 // Get System Manufacturer and Model
-func getSystemManufacturerAndModel() (string, string) {
+func getSystemManufacturerAndModel() (string, string, error) {
 	var systemInfo struct {
 		Manufacturer string
 		Model        string
@@ -149,17 +238,17 @@ func getSystemManufacturerAndModel() (string, string) {
 
 	err := wmi.Query("SELECT Manufacturer, Model FROM Win32_ComputerSystem", &systemInfo)
 	if err != nil {
-		return "Unknown", "Unknown"
+		return "", "", err
 	}
 
-	return systemInfo.Manufacturer, systemInfo.Model
+	return systemInfo.Manufacturer, systemInfo.Model, nil
 }
 
 // Get Kernel Version
 func getKernelVersion() string {
 	version, err := windows.RtlGetVersion()
 	if err != nil {
-		return "Unknown"
+		return ""
 	}
 	return fmt.Sprintf("%d.%d.%d", version.MajorVersion, version.MinorVersion, version.BuildNumber)
 }
@@ -169,7 +258,7 @@ func getSystemUptime() string {
 	var info windows.RTL_OSVERSIONINFOW
 	info.OSVersionInfoSize = uint32(unsafe.Sizeof(info))
 	if err := windows.RtlGetVersion(&info); err != nil {
-		return "Unknown"
+		return ""
 	}
 
 	// Uptime calculation using system tick count
@@ -188,14 +277,14 @@ func getSystemUptime() string {
 }
 
 // Get Installed Packages Count
-func getInstalledPackagesCount() int {
+func getInstalledPackagesCount() (int, error) {
 	var softwareList []struct{}
 	query := "SELECT * FROM Win32_Product"
 	err := wmi.Query(query, &softwareList)
 	if err != nil {
-		return 0
+		return 0, err
 	}
-	return len(softwareList)
+	return len(softwareList), nil
 }
 
 // Get Shell Name and Version
@@ -211,7 +300,7 @@ func getShellVersion() string {
 	}
 	output, err := cmd.Output()
 	if err != nil {
-		return "Unknown"
+		return ""
 	}
 	return string(output)
 }
@@ -221,7 +310,7 @@ func getPrimaryDisplayResolution() string {
 	var devMode windows.DEVMODE
 	devMode.Size = uint16(unsafe.Sizeof(devMode))
 	if !windows.EnumDisplaySettings(nil, windows.ENUM_CURRENT_SETTINGS, &devMode) {
-		return "Unknown"
+		return ""
 	}
 	return fmt.Sprintf("%dx%d", devMode.PelsWidth, devMode.PelsHeight)
 }
@@ -231,7 +320,7 @@ func getCurrentTheme() string {
 	var theme int32
 	const keyPath = `SOFTWARE\Microsoft\Windows\CurrentVersion\Themes\Personalize`
 	if err := windows.RegQueryValueEx(syscall.HKEY_CURRENT_USER, keyPath, "AppsUseLightTheme", &theme); err != nil {
-		return "Unknown"
+		return ""
 	}
 	if theme == 0 {
 		return "Dark Mode"
@@ -240,33 +329,33 @@ func getCurrentTheme() string {
 }
 
 // Get CPU Information
-func getCPUInfo() (model string, cores, threads int, speed struct{ Base, Boost string }) {
+func getCPUInfo() (model string, cores, threads int, speed struct{ Base, Boost string }, err error) {
 	var cpuInfo struct {
 		Name          string
 		NumberOfCores uint32
 		ThreadCount   uint32
 		MaxClockSpeed uint32
 	}
-	err := wmi.Query("SELECT Name, NumberOfCores, ThreadCount, MaxClockSpeed FROM Win32_Processor", &cpuInfo)
+	err = wmi.Query("SELECT Name, NumberOfCores, ThreadCount, MaxClockSpeed FROM Win32_Processor", &cpuInfo)
 	if err != nil {
-		return "Unknown", 0, 0, struct{ Base, Boost string }{"Unknown", "Unknown"}
+		return "", 0, 0, struct{ Base, Boost string }{"", ""}, err
 	}
 	model = cpuInfo.Name
 	cores = int(cpuInfo.NumberOfCores)
 	threads = int(cpuInfo.ThreadCount)
-	speed = struct{ Base, Boost string }{fmt.Sprintf("%d MHz", cpuInfo.MaxClockSpeed), "Unknown"} // Boost speed not available
+	speed = struct{ Base, Boost string }{fmt.Sprintf("%d MHz", cpuInfo.MaxClockSpeed), ""} // Boost speed not available
 	return
 }
 
 // Get GPU Information
-func getGPUInfo() (model, driverVersion string) {
+func getGPUInfo() (model, driverVersion string, err error) {
 	var gpuInfo struct {
 		Name          string
 		DriverVersion string
 	}
-	err := wmi.Query("SELECT Name, DriverVersion FROM Win32_VideoController", &gpuInfo)
+	err = wmi.Query("SELECT Name, DriverVersion FROM Win32_VideoController", &gpuInfo)
 	if err != nil {
-		return "Unknown", "Unknown"
+		return "", "", err
 	}
 	model = gpuInfo.Name
 	driverVersion = gpuInfo.DriverVersion
@@ -274,12 +363,12 @@ func getGPUInfo() (model, driverVersion string) {
 }
 
 // Get Memory Information
-func getMemoryInfo() (total, used, free uint64) {
+func getMemoryInfo() (total, used, free uint64, err error) {
 	var memStatus windows.MEMORYSTATUSEX
 	memStatus.Length = uint32(unsafe.Sizeof(memStatus))
-	err := windows.GlobalMemoryStatusEx(&memStatus)
+	err = windows.GlobalMemoryStatusEx(&memStatus)
 	if err != nil {
-		return 0, 0, 0
+		return 0, 0, 0, err
 	}
 	total = memStatus.TotalPhys
 	used = total - memStatus.AvailPhys
@@ -288,11 +377,11 @@ func getMemoryInfo() (total, used, free uint64) {
 }
 
 // Get Disk Information
-func getDiskInfo() (total, used, free uint64) {
+func getDiskInfo() (total, used, free uint64, err error) {
 	var freeBytesAvailable, totalNumberOfBytes, totalNumberOfFreeBytes uint64
-	err := windows.GetDiskFreeSpaceEx(nil, &freeBytesAvailable, &totalNumberOfBytes, &totalNumberOfFreeBytes)
+	err = windows.GetDiskFreeSpaceEx(nil, &freeBytesAvailable, &totalNumberOfBytes, &totalNumberOfFreeBytes)
 	if err != nil {
-		return 0, 0, 0
+		return 0, 0, 0, err
 	}
 	total = totalNumberOfBytes
 	used = totalNumberOfBytes - totalNumberOfFreeBytes
@@ -301,15 +390,15 @@ func getDiskInfo() (total, used, free uint64) {
 }
 
 // Get Battery Information
-func getBatteryInfo() (percentage int, status string) {
+func getBatteryInfo() (percentage int, status string, err error) {
 	var batteryInfo struct {
 		DesignCapacity     int
 		FullChargeCapacity int
 		Status             int
 	}
-	err := wmi.Query("SELECT DesignCapacity, FullChargeCapacity, BatteryStatus FROM Win32_Battery", &batteryInfo)
+	err = wmi.Query("SELECT DesignCapacity, FullChargeCapacity, BatteryStatus FROM Win32_Battery", &batteryInfo)
 	if err != nil {
-		return 0, "Unknown"
+		return 0, "", err
 	}
 	if batteryInfo.FullChargeCapacity > 0 {
 		percentage = (batteryInfo.DesignCapacity * 100) / batteryInfo.FullChargeCapacity
@@ -324,7 +413,7 @@ func getBatteryInfo() (percentage int, status string) {
 	case 3:
 		status = "Fully Charged"
 	default:
-		status = "Unknown"
+		status = ""
 	}
 	return
 }
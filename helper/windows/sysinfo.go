@@ -0,0 +1,101 @@
+package windows
+
+import (
+	"defetch/helper"
+	"time"
+)
+
+// ScreenResolution holds information about display resolution
+type ScreenResolution struct {
+	Resolution string `json:"resolution"`
+}
+
+// OtherInfo holds miscellaneous system information
+type OtherInfo struct {
+	KernelVersion    string             `json:"kernel_version"`
+	Uptime           string             `json:"uptime"`
+	ScreenResolution []ScreenResolution `json:"screen_resolution"`
+	CurrentTheme     string             `json:"current_theme"`
+}
+
+// PackageManagement holds information about installed packages
+type PackageManagement struct {
+	PackageCount int `json:"package_count"`
+}
+
+// Software holds software-related information
+type Software struct {
+	OSDetails          string `json:"os_details"`
+	ShellName          string `json:"shell_name"`
+	ShellVersion       string `json:"shell_version"`
+	DesktopEnvironment string `json:"desktop_environment"`
+	WindowManager      string `json:"window_manager"`
+}
+
+// CPUInfo holds processor information
+type CPUInfo struct {
+	Model      string `json:"model"`
+	Cores      int    `json:"cores"`
+	Threads    int    `json:"threads"`
+	BaseSpeed  string `json:"base_speed"`
+	BoostSpeed string `json:"boost_speed"`
+}
+
+// GPUInfo holds graphics adapter information
+type GPUInfo struct {
+	Model         string `json:"model"`
+	DriverVersion string `json:"driver_version"`
+}
+
+// MemoryInfo holds physical memory usage
+type MemoryInfo struct {
+	Total helper.Bytes `json:"total"`
+	Used  helper.Bytes `json:"used"`
+	Free  helper.Bytes `json:"free"`
+}
+
+// DiskInfo holds system drive usage
+type DiskInfo struct {
+	Total helper.Bytes `json:"total"`
+	Used  helper.Bytes `json:"used"`
+	Free  helper.Bytes `json:"free"`
+}
+
+// BatteryInfo holds battery state
+type BatteryInfo struct {
+	Percentage int    `json:"percentage"`
+	Status     string `json:"status"`
+}
+
+// HardwareInfo holds hardware-related information
+type HardwareInfo struct {
+	CPU     CPUInfo     `json:"cpu"`
+	GPU     GPUInfo     `json:"gpu"`
+	Memory  MemoryInfo  `json:"memory"`
+	Disk    DiskInfo    `json:"disk"`
+	Battery BatteryInfo `json:"battery"`
+}
+
+// HostInfo holds the identity of the machine
+type HostInfo struct {
+	Hostname     string `json:"hostname"`
+	CurrentUser  string `json:"current_user"`
+	OSName       string `json:"os_name"`
+	OSVersion    string `json:"os_version"`
+	Manufacturer string `json:"manufacturer"`
+	Model        string `json:"model"`
+}
+
+// SysInfo holds the system information
+type SysInfo struct {
+	SchemaVersion int       `json:"schema_version"` // Version of the JSON document layout
+	CollectedAt   time.Time `json:"collected_at"`   // When collection started
+	HostInfo
+	OtherInfo         OtherInfo                       `json:"other"`
+	PackageManagement PackageManagement               `json:"package_management"`
+	Software          Software                        `json:"software"`
+	Hardware          HardwareInfo                    `json:"hardware"`
+	Extra             map[string]any                  `json:"extra"`      // Values from collectors outside the built-in sections, keyed by collector name
+	Errors            map[string]*helper.SectionError `json:"errors"`     // Collector failures keyed by section
+	Timings           map[string]time.Duration        `json:"timings_ns"` // Wall time of each collector, keyed by collector name
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"defetch/helper"
+	"defetch/helper/logo"
+	"defetch/helper/windows"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// displayLogo prints the distribution logo next to a summary of sysInfo. A
+// non-empty logoPath replaces the built-in art but keeps its colours, and a
+// non-empty palette replaces the colours.
+func displayLogo(sysInfo interface{}, units helper.Units, logoPath string, palette []logo.Color) error {
+	var art *logo.Logo
+	var title string
+	var fields []logo.Field
+
+	switch sysInfo := sysInfo.(type) {
+	case helper.SysInfo:
+		art = logo.Detect(sysInfo.OSID, sysInfo.OSIDLike)
+		title = sysInfo.CurrentUser + "@" + sysInfo.Hostname
+		fields = linuxLogoFields(sysInfo, units)
+	case windows.SysInfo:
+		art = logo.Detect("windows", nil)
+		title = sysInfo.CurrentUser + "@" + sysInfo.Hostname
+		fields = windowsLogoFields(sysInfo, units)
+	}
+
+	if logoPath != "" {
+		custom, err := logo.Load(logoPath, art.Colors)
+		if err != nil {
+			return err
+		}
+		art = custom
+	}
+	if len(palette) > 0 {
+		art.Colors = palette
+	}
+
+	return logo.Render(os.Stdout, art, title, fields, useColor())
+}
+
+// useColor reports whether output should be coloured. In auto mode only
+// terminals get colours, unless NO_COLOR is set.
+func useColor() bool {
+	switch *colorFlag {
+	case "always":
+		return true
+	case "never":
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	stat, err := os.Stdout.Stat()
+	return err == nil && stat.Mode()&os.ModeCharDevice != 0
+}
+
+// linuxLogoFields picks the fields shown next to the logo on Linux
+func linuxLogoFields(sysInfo helper.SysInfo, units helper.Units) []logo.Field {
+	var packages string
+	if sysInfo.PackageManagement.PackageCount > 0 {
+		packages = fmt.Sprintf("%d (%s)", sysInfo.PackageManagement.PackageCount,
+			strings.Join(sysInfo.PackageManagement.PackageManagers, ", "))
+	}
+
+	var resolution string
+	if len(sysInfo.OtherInfo.ScreenResolution) > 0 {
+		resolution = sysInfo.OtherInfo.ScreenResolution[0].Resolution
+	}
+
+	var cpu string
+	if sysInfo.CPU.ModelName != "" {
+		cpu = fmt.Sprintf("%s (%d)", sysInfo.CPU.ModelName, sysInfo.CPU.Threads)
+		if sysInfo.CPU.Frequency > 0 {
+			cpu += fmt.Sprintf(" @ %.2f GHz", sysInfo.CPU.Frequency/1000)
+		}
+	}
+
+	var gpu string
+	for _, info := range sysInfo.GPU {
+		if info.Primary {
+			gpu = strings.TrimSpace(info.Vendor + " " + info.ModelName)
+		}
+	}
+
+	var memory string
+	if sysInfo.Memory.TotalSize > 0 {
+		memory = sysInfo.Memory.UsedSize.Format(units) + " / " + sysInfo.Memory.TotalSize.Format(units)
+	}
+
+	// The disk line shows the file system mounted as root, and a benchmark
+	// gets a line of its own
+	var disk, bench string
+	for _, storage := range sysInfo.Storage {
+		if b := storage.Benchmark; b != nil {
+			bench = fmt.Sprintf("%s/s read, %s/s write, %.0f / %.0f IOPS (%s)",
+				b.SequentialRead.Speed.Format(units), b.SequentialWrite.Speed.Format(units),
+				b.RandomRead.IOPS, b.RandomWrite.IOPS, b.MountPoint)
+		}
+		for _, mount := range storage.AllMounts() {
+			if mount.MountPoint == "/" {
+				disk = mount.Used.Format(units) + " / " + (mount.Used + mount.Available).Format(units)
+			}
+		}
+	}
+
+	// Batteries of mice and keyboards have the Device scope
+	var battery string
+	for _, info := range sysInfo.Power.Batteries {
+		if info.Scope != "Device" && battery == "" {
+			battery = fmt.Sprintf("%.0f%% [%s]", info.Percentage, info.Status)
+		}
+	}
+
+	return []logo.Field{
+		{Label: "OS", Value: strings.TrimSpace(sysInfo.OSName + " " + sysInfo.OSVersion + " " + sysInfo.Architecture)},
+		{Label: "Host", Value: strings.TrimSpace(sysInfo.Motherboard.Manufacturer + " " + sysInfo.Motherboard.Model)},
+		{Label: "Kernel", Value: sysInfo.KernelVersion},
+		{Label: "Uptime", Value: sysInfo.Uptime},
+		{Label: "Packages", Value: packages},
+		{Label: "Shell", Value: sysInfo.Shell},
+		{Label: "Resolution", Value: resolution},
+		{Label: "DE", Value: strings.TrimSpace(sysInfo.Software.DesktopEnvironment)},
+		{Label: "WM", Value: strings.TrimSpace(sysInfo.Software.WindowManager)},
+		{Label: "Theme", Value: strings.Trim(sysInfo.Software.GTKTheme, "'")},
+		{Label: "Icons", Value: strings.Trim(sysInfo.Software.IconsTheme, "'")},
+		{Label: "CPU", Value: cpu},
+		{Label: "GPU", Value: gpu},
+		{Label: "Memory", Value: memory},
+		{Label: "Disk (/)", Value: disk},
+		{Label: "Disk Bench", Value: bench},
+		{Label: "Battery", Value: battery},
+	}
+}
+
+// windowsLogoFields picks the fields shown next to the logo on Windows
+func windowsLogoFields(sysInfo windows.SysInfo, units helper.Units) []logo.Field {
+	var resolution string
+	if len(sysInfo.OtherInfo.ScreenResolution) > 0 {
+		resolution = sysInfo.OtherInfo.ScreenResolution[0].Resolution
+	}
+
+	var packages string
+	if sysInfo.PackageManagement.PackageCount > 0 {
+		packages = fmt.Sprint(sysInfo.PackageManagement.PackageCount)
+	}
+
+	var cpu string
+	if sysInfo.Hardware.CPU.Model != "" {
+		cpu = fmt.Sprintf("%s (%d)", sysInfo.Hardware.CPU.Model, sysInfo.Hardware.CPU.Threads)
+	}
+
+	var memory, disk string
+	if sysInfo.Hardware.Memory.Total > 0 {
+		memory = sysInfo.Hardware.Memory.Used.Format(units) + " / " + sysInfo.Hardware.Memory.Total.Format(units)
+	}
+	if sysInfo.Hardware.Disk.Total > 0 {
+		disk = sysInfo.Hardware.Disk.Used.Format(units) + " / " + sysInfo.Hardware.Disk.Total.Format(units)
+	}
+
+	return []logo.Field{
+		{Label: "OS", Value: strings.TrimSpace(sysInfo.OSName + " " + sysInfo.OSVersion)},
+		{Label: "Host", Value: strings.TrimSpace(sysInfo.Manufacturer + " " + sysInfo.Model)},
+		{Label: "Kernel", Value: sysInfo.OtherInfo.KernelVersion},
+		{Label: "Uptime", Value: sysInfo.OtherInfo.Uptime},
+		{Label: "Packages", Value: packages},
+		{Label: "Shell", Value: strings.TrimSpace(sysInfo.Software.ShellName + " " + sysInfo.Software.ShellVersion)},
+		{Label: "Resolution", Value: resolution},
+		{Label: "DE", Value: sysInfo.Software.DesktopEnvironment},
+		{Label: "WM", Value: sysInfo.Software.WindowManager},
+		{Label: "Theme", Value: sysInfo.OtherInfo.CurrentTheme},
+		{Label: "CPU", Value: cpu},
+		{Label: "GPU", Value: sysInfo.Hardware.GPU.Model},
+		{Label: "Memory", Value: memory},
+		{Label: "Disk", Value: disk},
+	}
+}
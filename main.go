@@ -1,208 +1,347 @@
 package main
 
 import (
-	"defetch/helper/linux"
+	"context"
+	"defetch/helper"
+	"defetch/helper/config"
+	"defetch/helper/layout"
+	"defetch/helper/logo"
 	"defetch/helper/windows"
+	"flag"
 	"fmt"
-	"runtime"
+	"io/fs"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var (
+	strict    = flag.Bool("strict", false, "fail instead of printing a partial report when a section cannot be collected")
+	workers   = flag.Int("workers", 4, "number of collectors run concurrently")
+	timeout   = flag.Duration("timeout", 10*time.Second, "deadline for each collector, 0 to wait indefinitely")
+	timings   = flag.Bool("timings", false, "print the wall time of each collector to stderr")
+	format    = flag.String("format", "text", "output format: text, json, logo or an inline template such as '{{.CPU.ModelName}}'")
+	unitsFlag = flag.String("units", "iec", "units for sizes in text output: iec, si or bytes (JSON always uses bytes)")
+	logoFile  = flag.String("logo", "", "draw the ASCII art in `file` instead of the distribution logo (implies -format logo)")
+	tmplFile  = flag.String("template", "", "print the output through the text/template layout in `file`")
+	colorFlag = flag.String("color", "auto", "colour output: auto, always or never")
+	cfgFile   = flag.String("config", "", "read settings from `file` instead of $XDG_CONFIG_HOME/defetch/config.toml")
+	root      = flag.String("root", "", "read files below `dir`, e.g. a mounted disk image, instead of the running system")
+	record    = flag.String("record", "", "store the output of every command run in `dir`")
+	replay    = flag.String("replay", "", "serve commands from the recordings in `dir` instead of running them")
 )
 
 func main() {
-	switch runtime.GOOS {
-	case "linux":
-		sysInfo := linux.GetLinuxInfo()
-		displaySystemInfo(sysInfo)
-	case "windows":
-		sysInfo := windows.GetWindowsInfo()
-		displaySystemInfo(sysInfo)
+	flag.Parse()
+
+	var bench *benchRequest
+	switch flag.Arg(0) {
+	case "":
+	case "bench":
+		if bench = parseBench(flag.Args()[1:]); bench == nil {
+			os.Exit(2)
+		}
+	case "schema":
+		schema, err := helper.MarshalSchema()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "defetch: %v\n", err)
+			os.Exit(1)
+		}
+		os.Stdout.Write(schema)
+		return
+	case "usb":
+		os.Exit(runUSB(flag.Args()[1:]))
 	default:
-		fmt.Println("Unsupported operating system.")
+		fmt.Fprintf(os.Stderr, "defetch: unknown command %q\n", flag.Arg(0))
+		os.Exit(2)
 	}
-}
 
-func displaySystemInfo(sysInfo interface{}) {
-	if info, ok := sysInfo.(linux.SysInfo); ok {
-
-		sysInfo := linux.GetLinuxInfo()
-
-		fmt.Printf("Hostname: %s\n", sysInfo.Hostname)
-		fmt.Printf("Current User: %s\n", sysInfo.CurrentUser)
-		fmt.Printf("Operating System: %s %s (%s)\n", sysInfo.OSName, sysInfo.OSVersion, sysInfo.OSCodename)
-		fmt.Printf("Kernel Version: %s\n", sysInfo.KernelVersion)
-		fmt.Printf("Shell: %s\n", sysInfo.Shell)
-		fmt.Printf("Shell Version: %s\n", sysInfo.ShellVersion)
-		fmt.Printf("Architecture: %s\n", sysInfo.Architecture)
-		fmt.Printf("Uptime: %s\n", sysInfo.Uptime)
-
-		// CPU Information
-		fmt.Printf("CPU Model: %s\n", sysInfo.CPU.ModelName)
-		fmt.Printf("CPU Cores: %d\n", sysInfo.CPU.Cores)
-		fmt.Printf("CPU Threads: %d\n", sysInfo.CPU.Threads)
-		fmt.Printf("CPU Architecture: %s\n", sysInfo.CPU.Architecture)
-		fmt.Printf("CPU Frequency: %.2f MHz\n", sysInfo.CPU.Frequency)
-		fmt.Printf("CPU Cache Size: %d KB\n", sysInfo.CPU.CacheSize)
-		fmt.Printf("CPU Flags: %s\n", sysInfo.CPU.Flags)
-
-		// GPU Information
-		fmt.Printf("GPU Model: %s\n", sysInfo.GPU.ModelName)
-		fmt.Printf("GPU Driver Version: %s\n", sysInfo.GPU.DriverVersion)
-		fmt.Printf("GPU Memory Size: %s\n", sysInfo.GPU.MemorySize)
-
-		// Motherboard Information
-		fmt.Printf("Motherboard Manufacturer: %s\n", sysInfo.Motherboard.Manufacturer)
-		fmt.Printf("Motherboard Model: %s\n", sysInfo.Motherboard.Model)
-		fmt.Printf("BIOS/UEFI Version: %s\n", sysInfo.Motherboard.BIOSVersion)
-		fmt.Printf("Motherboard Serial Number: %s\n", sysInfo.Motherboard.SerialNumber)
-
-		// Memory Information
-		fmt.Printf("Total Memory: %s\n", sysInfo.Memory.TotalSize)
-		fmt.Printf("Used Memory: %s\n", sysInfo.Memory.UsedSize)
-		fmt.Printf("Free Memory: %s\n", sysInfo.Memory.FreeSize)
-		fmt.Printf("Memory Slots: %v\n", sysInfo.Memory.Slots)
-
-		// Storage Information
-		for _, storage := range sysInfo.Storage {
-			fmt.Printf("Device: %s\n", storage.Device)
-			fmt.Printf("Model: %s\n", storage.Model)
-			fmt.Printf("Capacity: %s\n", storage.Capacity)
-			fmt.Printf("Used: %s\n", storage.Used)
-			fmt.Printf("Available: %s\n", storage.Available)
-			fmt.Printf("File System: %s\n", storage.FileSystem)
-			fmt.Printf("Mount Point: %s\n", storage.MountPoint)
-			fmt.Printf("Read Speed: %s\n", storage.ReadSpeed)
-			fmt.Printf("Write Speed: %s\n", storage.WriteSpeed)
-		}
+	cfg, err := config.Load(*cfgFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "defetch: %v\n", err)
+		os.Exit(2)
+	}
+	if err := applyConfig(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "defetch: %v\n", err)
+		os.Exit(2)
+	}
+	// A benchmark only needs the disk it stores its results with
+	sections := cfg.Sections
+	if bench != nil {
+		sections = []string{helper.SectionStorage}
+	}
+	collectors, err := helper.SelectCollectors(sections)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "defetch: %v\n", err)
+		os.Exit(2)
+	}
+	timeouts, settings, err := cfg.RunOptions()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "defetch: %v\n", err)
+		os.Exit(2)
+	}
+	palette, err := parsePalette(cfg.Colors)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "defetch: %v\n", err)
+		os.Exit(2)
+	}
 
-		// Network Information
-		for _, network := range sysInfo.Network {
-			fmt.Printf("Interface: %s\n", network.InterfaceName)
-			fmt.Printf("IP Address: %s\n", network.IPAddress)
-			fmt.Printf("MAC Address: %s\n", network.MACAddress)
-			fmt.Printf("Network Speed: %s\n", network.Speed)
-			fmt.Printf("Active: %t\n", network.Active)
-			fmt.Printf("Default Gateway: %s\n", network.DefaultGateway)
-		}
+	layoutText, err := loadLayout()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "defetch: %v\n", err)
+		os.Exit(2)
+	}
+	units, err := helper.ParseUnits(*unitsFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "defetch: %v\n", err)
+		os.Exit(2)
+	}
+	if *colorFlag != "auto" && *colorFlag != "always" && *colorFlag != "never" {
+		fmt.Fprintf(os.Stderr, "defetch: unknown colour mode %q\n", *colorFlag)
+		os.Exit(2)
+	}
 
-		// // Battery Information (TODO LATER)
-		// fmt.Printf("Battery Status: %s\n", sysInfo.Battery.Status)
-		// fmt.Printf("Battery Capacity: %s\n", sysInfo.Battery.Capacity)
-		// fmt.Printf("Battery Percentage: %s\n", sysInfo.Battery.Percentage)
-		// fmt.Printf("Battery Manufacturer: %s\n", sysInfo.Battery.Manufacturer)
-		// fmt.Printf("Battery Model: %s\n", sysInfo.Battery.Model)
-
-		// Peripherals Information
-		fmt.Println("Connected Devices:")
-		for _, device := range sysInfo.Peripherals.ConnectedDevices {
-			fmt.Printf("- %s\n", device)
-		}
+	runner, err := commandRunner()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "defetch: %v\n", err)
+		os.Exit(2)
+	}
 
-		fmt.Println("USB Devices:")
-		for _, usbDevice := range sysInfo.Peripherals.USBDevices {
-			fmt.Printf("- Name: %s, Vendor: %s, Product ID: %s, Vendor ID: %s\n", usbDevice.Name, usbDevice.Vendor, usbDevice.ProductID, usbDevice.VendorID)
-		}
+	// Interrupting a benchmark must still remove its test file
+	ctx := context.Background()
+	if bench != nil {
+		var stop context.CancelFunc
+		ctx, stop = signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		defer stop()
+	}
 
-		fmt.Println("Audio Devices:")
-		for _, audioDevice := range sysInfo.Peripherals.AudioDevices {
-			fmt.Printf("- %s\n", audioDevice)
+	sysInfo := getSystemInfo(ctx, collectors, helper.RunOptions{
+		Workers:  *workers,
+		Timeout:  *timeout,
+		Timeouts: timeouts,
+		Settings: settings,
+		FS:       rootFS(),
+		Runner:   runner,
+	})
+	if sysInfo == nil {
+		fmt.Println("Unsupported operating system.")
+		return
+	}
+	if bench != nil {
+		if err := bench.run(ctx, sysInfo); err != nil {
+			fmt.Fprintf(os.Stderr, "defetch: bench: %v\n", err)
+			os.Exit(1)
 		}
+	}
 
-		fmt.Println("Printer Details:")
-		for _, printer := range sysInfo.Peripherals.PrinterDetails {
-			fmt.Printf("- %s\n", printer)
+	if errs := sectionErrors(sysInfo); *strict && len(errs) > 0 {
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "defetch: %v\n", err)
 		}
-
-		// Software Information
-		fmt.Printf("Operating System: %s\n", sysInfo.Software.OSDetails)
-		fmt.Printf("Desktop Environment: %s\n", sysInfo.Software.DesktopEnvironment)
-		fmt.Printf("Window Manager: %s\n", sysInfo.Software.WindowManager)
-		fmt.Printf("WM Theme: %s\n", sysInfo.Software.WMTheme)
-		fmt.Printf("GTK Theme: %s\n", sysInfo.Software.GTKTheme)
-		fmt.Printf("Icons Theme: %s\n", sysInfo.Software.IconsTheme)
-		fmt.Printf("Font: %s\n", sysInfo.Software.Font)
-
-		// Browser Information
-		fmt.Println("Browsers:")
-		for _, browser := range sysInfo.Software.Browser {
-			fmt.Printf("  %s: %s\n", browser.Name, browser.Version)
+		os.Exit(1)
+	}
+	switch *format {
+	case "json":
+		if err := helper.WriteJSON(os.Stdout, sysInfo); err != nil {
+			fmt.Fprintf(os.Stderr, "defetch: %v\n", err)
+			os.Exit(1)
 		}
-
-		// Running Processes Information
-		fmt.Printf("\nNumber of Running Processes: %d\n", len(sysInfo.Software.RunningProcesses))
-		fmt.Println("Top Processes by CPU Usage:")
-		for _, process := range sysInfo.Software.RunningProcesses {
-			fmt.Printf("  PID: %d, Name: %s, CPU Usage: %.2f%%, Memory Usage: %.2f%%\n",
-				process.PID, process.Name, process.CPUUsage, process.MemoryUsage)
+	case "logo":
+		if err := displayLogo(sysInfo, units, *logoFile, palette); err != nil {
+			fmt.Fprintf(os.Stderr, "defetch: %v\n", err)
+			os.Exit(1)
+		}
+	default:
+		if err := displayLayout(sysInfo, units, layoutText, sectionOrder(collectors)); err != nil {
+			fmt.Fprintf(os.Stderr, "defetch: %v\n", err)
+			os.Exit(1)
 		}
+	}
+	if *timings {
+		displayTimings(sysInfo)
+	}
+}
 
-		// Startup Programs Information
-		fmt.Println("\nStartup Programs:")
-		for _, program := range sysInfo.Software.StartupPrograms {
-			fmt.Printf("  Name: %s, Command: %s\n", program.Name, program.Command)
+// rootFS returns the file system given by --root, or nil for the running system
+func rootFS() fs.FS {
+	if *root == "" {
+		return nil
+	}
+	return helper.RootFS(*root)
+}
+
+// commandRunner returns the Runner selected by --record or --replay, or nil
+// to run commands on the running system
+func commandRunner() (helper.Runner, error) {
+	switch {
+	case *record != "" && *replay != "":
+		return nil, fmt.Errorf("--record and --replay cannot be combined")
+	case *record != "":
+		return helper.RecordRunner(*record)
+	case *replay != "":
+		return helper.ReplayRunner(*replay), nil
+	}
+	return nil, nil
+}
+
+// loadLayout returns the template given by --template or an inline --format.
+// An empty layout selects the built-in one for the platform. Templates only
+// apply to text output, unless --template is given without --format.
+func loadLayout() (string, error) {
+	switch {
+	case *tmplFile != "" && (*format == "text" || (flagSet("template") && !flagSet("format"))):
+		data, err := os.ReadFile(*tmplFile)
+		if err != nil {
+			return "", err
+		}
+		*format = "text"
+		return string(data), nil
+	case strings.Contains(*format, "{{"):
+		text := *format
+		if !strings.HasSuffix(text, "\n") {
+			text += "\n"
 		}
+		*format = "text"
+		return text, nil
+	case *format != "text" && *format != "json" && *format != "logo":
+		return "", fmt.Errorf("unknown format %q", *format)
+	}
+	return "", nil
+}
 
-		// System Performance Information
-		fmt.Printf("\nOverall CPU Usage: %.2f%%\n", sysInfo.Performance.CPUUsage)
-		fmt.Println("Per-Core CPU Usage:")
-		for i, usage := range sysInfo.Performance.PerCoreUsage {
-			fmt.Printf("  Core %d: %.2f%%\n", i, usage)
+// displayLayout prints sysInfo through a text/template layout. The built-in
+// layout prints sections in the given order.
+func displayLayout(sysInfo interface{}, units helper.Units, text string, sections []string) error {
+	if text == "" {
+		builtin, err := layout.Builtin(sysInfo, sections)
+		if err != nil {
+			return err
 		}
+		text = builtin
+	}
+	return layout.Render(os.Stdout, text, sysInfo, layout.Options{Units: units, Color: useColor()})
+}
 
-		fmt.Printf("\nTotal Memory Used: %s\n", sysInfo.Performance.MemoryUsage.TotalUsed)
-		fmt.Printf("Free Memory: %s\n", sysInfo.Performance.MemoryUsage.Free)
-		fmt.Printf("Total Memory: %s\n", sysInfo.Performance.MemoryUsage.Total)
+// applyConfig copies the values of the config file to the flags that were not
+// given on the command line
+func applyConfig(cfg config.Config) error {
+	values := map[string]string{
+		"format":   cfg.Format,
+		"units":    cfg.Units,
+		"template": cfg.Template,
+		"logo":     cfg.Logo,
+		"color":    cfg.Color,
+		"timeout":  cfg.Timeout,
+	}
+	if cfg.Workers > 0 {
+		values["workers"] = strconv.Itoa(cfg.Workers)
+	}
+	if cfg.Strict != nil {
+		values["strict"] = strconv.FormatBool(*cfg.Strict)
+	}
+	if cfg.Timings != nil {
+		values["timings"] = strconv.FormatBool(*cfg.Timings)
+	}
 
-		fmt.Println("\nTop Applications by Memory Usage:")
-		for _, app := range sysInfo.Performance.PerAppMemoryUsage {
-			fmt.Printf("  PID: %d, Name: %s, Memory Usage: %.2f%%\n",
-				app.PID, app.Name, app.MemoryUsage)
+	// Values are set on the flags directly so that flagSet keeps telling what
+	// was given on the command line
+	for name, value := range values {
+		if value == "" || flagSet(name) {
+			continue
 		}
+		if err := flag.Lookup(name).Value.Set(value); err != nil {
+			return fmt.Errorf("config: %s: %w", name, err)
+		}
+	}
+
+	// A custom logo only makes sense in logo output, so ask for it implicitly
+	if *logoFile != "" && !flagSet("format") && cfg.Format == "" {
+		*format = "logo"
+	}
+	return nil
+}
 
-		// Package Management Information
-		fmt.Printf("\nNumber of Installed Packages: %d\n", sysInfo.PackageManagement.PackageCount)
-		fmt.Printf("Number of Available Updates: %d\n", sysInfo.PackageManagement.AvailableUpdates)
-		fmt.Printf("Used Package Managers: %v\n", sysInfo.PackageManagement.PackageManagers)
-		fmt.Println("Recently Installed Packages:")
-		for _, pkg := range sysInfo.PackageManagement.RecentlyInstalledPackages {
-			fmt.Printf("  Name: %s, Version: %s, Installed Date: %s\n",
-				pkg.Name, pkg.Version, pkg.InstalledDate)
+// parsePalette converts the colour names of the config file
+func parsePalette(names []string) ([]logo.Color, error) {
+	var palette []logo.Color
+	for _, name := range names {
+		c, err := logo.ParseColor(name)
+		if err != nil {
+			return nil, fmt.Errorf("config: colors: %w", err)
 		}
+		palette = append(palette, c)
+	}
+	return palette, nil
+}
 
-		// Other Information
-		fmt.Printf("\nPublic IP: %s\n", sysInfo.OtherInfo.PublicIP)
-		fmt.Printf("Timezone: %s\n", sysInfo.OtherInfo.Timezone)
-		fmt.Printf("Locale: %s\n", sysInfo.OtherInfo.Locale)
-		fmt.Printf("System Language: %s\n", sysInfo.OtherInfo.SystemLanguage)
-		fmt.Printf("CPU Temperature: %.2f°C\n", sysInfo.OtherInfo.Temperature.CPU)
-		fmt.Printf("GPU Temperature: %.2f°C\n", sysInfo.OtherInfo.Temperature.GPU)
-		fmt.Printf("Motherboard Temperature: %.2f°C\n", sysInfo.OtherInfo.Temperature.Motherboard)
-		fmt.Println("Screen Resolution:")
-		for _, screen := range sysInfo.OtherInfo.ScreenResolution {
-			fmt.Printf("  Model: %s, Resolution: %s, Refresh Rate: %d Hz\n",
-				screen.Model, screen.Resolution, screen.RefreshRate)
+// sectionOrder returns the sections of collectors in the order they first appear
+func sectionOrder(collectors []helper.Collector) []string {
+	var sections []string
+	seen := make(map[string]bool)
+	for _, c := range collectors {
+		if !seen[c.Section()] {
+			seen[c.Section()] = true
+			sections = append(sections, c.Section())
 		}
-		fmt.Println("Disk Partitions:")
-		for _, partition := range sysInfo.OtherInfo.DiskPartitions {
-			fmt.Printf("  Device: %s, Filesystem: %s, Mount Point: %s, Size: %s, Used: %s, Available: %s\n",
-				partition.Device, partition.Filesystem, partition.MountPoint, partition.Size, partition.Used, partition.Available)
+	}
+	return sections
+}
+
+// flagSet reports whether the named flag was given on the command line
+func flagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
 		}
-	} else if info, ok := sysInfo.(windows.SysInfo); ok {
-		// Retrieve and print Windows system information
-		sysInfo := windows.GetWindowsInfo()
-
-		fmt.Printf("Hostname: %s\n", sysInfo.Hostname)
-		fmt.Printf("Current User: %s\n", sysInfo.CurrentUser)
-		fmt.Printf("OS Name: %s\n", sysInfo.OSName)
-		fmt.Printf("OS Version: %s\n", sysInfo.OSVersion)
-		fmt.Printf("Manufacturer: %s\n", sysInfo.Manufacturer)
-		fmt.Printf("Model: %s\n", sysInfo.Model)
-		fmt.Printf("Kernel Version: %s\n", sysInfo.OtherInfo.KernelVersion)
-		fmt.Printf("System Uptime: %s\n", sysInfo.OtherInfo.Uptime)
-		fmt.Printf("Installed Packages: %d\n", sysInfo.PackageManagement.PackageCount)
-		fmt.Printf("Shell Name: %s\n", sysInfo.Software.ShellName)
-		fmt.Printf("Shell Version: %s\n", sysInfo.Software.ShellVersion)
-		fmt.Printf("Primary Display Resolution: %s\n", sysInfo.OtherInfo.ScreenResolution[0].Resolution)
-		fmt.Printf("Desktop Environment: %s\n", sysInfo.Software.DesktopEnvironment)
-		fmt.Printf("Window Manager: %s\n", sysInfo.Software.WindowManager)
-		fmt.Printf("Current Theme: %s\n", sysInfo.OtherInfo.CurrentTheme)
+	})
+	return set
+}
+
+// displayTimings prints how long each collector took, slowest first
+func displayTimings(sysInfo interface{}) {
+	var durations map[string]time.Duration
+	switch sysInfo := sysInfo.(type) {
+	case helper.SysInfo:
+		durations = sysInfo.Timings
+	case windows.SysInfo:
+		durations = sysInfo.Timings
+	}
+
+	names := make([]string, 0, len(durations))
+	for name := range durations {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return durations[names[i]] > durations[names[j]]
+	})
+
+	fmt.Fprintln(os.Stderr, "Collector Timings:")
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-12s %s\n", name, durations[name].Round(time.Millisecond))
+	}
+}
+
+// sectionErrors returns the collector failures recorded in sysInfo, ordered by section
+func sectionErrors(sysInfo interface{}) []*helper.SectionError {
+	var errs map[string]*helper.SectionError
+	switch sysInfo := sysInfo.(type) {
+	case helper.SysInfo:
+		errs = sysInfo.Errors
+	case windows.SysInfo:
+		errs = sysInfo.Errors
+	}
+
+	sorted := make([]*helper.SectionError, 0, len(errs))
+	for _, err := range errs {
+		sorted = append(sorted, err)
 	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Section < sorted[j].Section
+	})
+	return sorted
 }
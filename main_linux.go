@@ -0,0 +1,16 @@
+package main
+
+import (
+	"context"
+	"defetch/helper"
+	"defetch/helper/linux"
+	"time"
+)
+
+func getSystemInfo(ctx context.Context, collectors []helper.Collector, opts helper.RunOptions) interface{} {
+	return linux.GetLinuxInfo(ctx, collectors, opts)
+}
+
+func benchmarkDisk(ctx context.Context, storages []helper.StorageInfo, dir string, size helper.Bytes, duration time.Duration) error {
+	return linux.BenchmarkMount(ctx, storages, dir, linux.BenchmarkOptions{Size: size, Duration: duration})
+}
@@ -0,0 +1,18 @@
+//go:build !linux && !windows
+
+package main
+
+import (
+	"context"
+	"defetch/helper"
+	"errors"
+	"time"
+)
+
+func getSystemInfo(ctx context.Context, collectors []helper.Collector, opts helper.RunOptions) interface{} {
+	return nil
+}
+
+func benchmarkDisk(ctx context.Context, storages []helper.StorageInfo, dir string, size helper.Bytes, duration time.Duration) error {
+	return errors.New("not supported on this operating system")
+}
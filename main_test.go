@@ -0,0 +1,94 @@
+package main
+
+import (
+	"defetch/helper"
+	"defetch/helper/config"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Helper function to parse args into the flags of defetch as if given on the
+// command line, restoring the flags when the test is done
+func parseFlags(t *testing.T, args ...string) {
+	t.Helper()
+	saved := flag.CommandLine
+	flags := flag.NewFlagSet("defetch", flag.ContinueOnError)
+	values := make(map[string]string)
+	saved.VisitAll(func(f *flag.Flag) {
+		if strings.HasPrefix(f.Name, "test.") {
+			return
+		}
+		values[f.Name] = f.Value.String()
+		f.Value.Set(f.DefValue)
+		flags.Var(f.Value, f.Name, f.Usage)
+	})
+	flag.CommandLine = flags
+	t.Cleanup(func() {
+		flag.CommandLine = saved
+		for name, value := range values {
+			saved.Lookup(name).Value.Set(value)
+		}
+	})
+
+	if err := flags.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConfigTemplate(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "layout.tmpl")
+	if err := os.WriteFile(file, []byte("{{.Hostname}}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		args   []string
+		cfg    config.Config
+		format string
+		layout string
+	}{
+		{nil, config.Config{Template: file}, "text", "{{.Hostname}}\n"},
+		{[]string{"--format", "text"}, config.Config{Template: file}, "text", "{{.Hostname}}\n"},
+		// Other formats, from the command line or the file, win over the template
+		{[]string{"--format", "json"}, config.Config{Template: file}, "json", ""},
+		{[]string{"--format", "logo"}, config.Config{Template: file}, "logo", ""},
+		{[]string{"--format", "{{.OSName}}"}, config.Config{Template: file}, "text", "{{.OSName}}\n"},
+		{[]string{"--logo", "logo.txt"}, config.Config{Template: file}, "logo", ""},
+		{nil, config.Config{Format: "logo", Template: file}, "logo", ""},
+		{nil, config.Config{Format: "json", Template: file}, "json", ""},
+		{nil, config.Config{Format: "text", Template: file, Logo: "logo.txt"}, "text", "{{.Hostname}}\n"},
+		{nil, config.Config{Logo: "logo.txt"}, "logo", ""},
+		// A template on the command line wins over the format of the file
+		{[]string{"--template", file}, config.Config{Format: "logo"}, "text", "{{.Hostname}}\n"},
+		{[]string{"--format", "logo"}, config.Config{Format: "json"}, "logo", ""},
+	}
+	for _, test := range tests {
+		parseFlags(t, test.args...)
+		if err := applyConfig(test.cfg); err != nil {
+			t.Fatal(err)
+		}
+		layoutText, err := loadLayout()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *format != test.format || layoutText != test.layout {
+			t.Errorf("%q with %+v: got format %q and layout %q, want %q and %q",
+				test.args, test.cfg, *format, layoutText, test.format, test.layout)
+		}
+	}
+}
+
+func TestConfigUnknownCollector(t *testing.T) {
+	cfg := config.Config{Collectors: map[string]helper.Settings{"softwar": {"processes": int64(10)}}}
+	if _, _, err := cfg.RunOptions(); err == nil {
+		t.Error("got no error for an unknown collector")
+	}
+
+	cfg.Collectors = map[string]helper.Settings{"software": {"processes": int64(10)}}
+	if _, settings, err := cfg.RunOptions(); err != nil || settings["software"] == nil {
+		t.Errorf("got %v, %v for a registered collector", settings, err)
+	}
+}
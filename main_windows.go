@@ -0,0 +1,17 @@
+package main
+
+import (
+	"context"
+	"defetch/helper"
+	"defetch/helper/windows"
+	"errors"
+	"time"
+)
+
+func getSystemInfo(ctx context.Context, collectors []helper.Collector, opts helper.RunOptions) interface{} {
+	return windows.GetWindowsInfo(ctx, collectors, opts)
+}
+
+func benchmarkDisk(ctx context.Context, storages []helper.StorageInfo, dir string, size helper.Bytes, duration time.Duration) error {
+	return errors.New("not supported on this operating system")
+}
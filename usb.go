@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"defetch/helper"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// runUSB implements `defetch usb`, which lists the USB devices and returns the
+// exit status
+func runUSB(args []string) int {
+	flags := flag.NewFlagSet("usb", flag.ContinueOnError)
+	tree := flags.Bool("tree", false, "show devices below the hubs they are plugged into")
+	format := flags.String("format", "text", "output format: text or json")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "defetch: unknown format %q\n", *format)
+		return 2
+	}
+
+	// Only the usb collector, as the rest of the section runs external tools
+	collectors, err := helper.SelectCollectors([]string{"usb"})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "defetch: usb: %v\n", err)
+		return 1
+	}
+	runner, err := commandRunner()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "defetch: usb: %v\n", err)
+		return 2
+	}
+	opts := helper.RunOptions{Workers: 1, Timeout: *timeout, FS: rootFS(), Runner: runner}
+	sysInfo, ok := getSystemInfo(context.Background(), collectors, opts).(helper.SysInfo)
+	if !ok {
+		fmt.Fprintln(os.Stderr, "defetch: usb: not supported on this operating system")
+		return 1
+	}
+	if err := sysInfo.Errors[helper.SectionPeripherals]; err != nil {
+		fmt.Fprintf(os.Stderr, "defetch: usb: %v\n", err)
+		return 1
+	}
+
+	devices := sysInfo.Peripherals.USBDevices
+	switch {
+	case *format == "json":
+		err = helper.WriteJSON(os.Stdout, devices)
+	case *tree:
+		err = displayUSBTree(os.Stdout, devices)
+	default:
+		err = displayUSBList(os.Stdout, devices)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "defetch: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// displayUSBList prints one line per device, like lsusb
+func displayUSBList(w io.Writer, devices []helper.USBDeviceInfo) error {
+	for _, device := range devices {
+		if _, err := fmt.Fprintf(w, "Bus %03d Device %03d: %s\n", device.Bus, device.DeviceNumber, describeUSB(device)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// displayUSBTree prints the devices below the hubs they are plugged into
+func displayUSBTree(w io.Writer, devices []helper.USBDeviceInfo) error {
+	children := make(map[string][]helper.USBDeviceInfo)
+	for _, device := range devices {
+		children[device.Parent] = append(children[device.Parent], device)
+	}
+
+	var out strings.Builder
+	var walk func(parent, indent string)
+	walk = func(parent, indent string) {
+		for i, device := range children[parent] {
+			branch, next := "├── ", "│   "
+			if i == len(children[parent])-1 {
+				branch, next = "└── ", "    "
+			}
+			fmt.Fprintf(&out, "%s%s%s: %s\n", indent, branch, device.Path, describeUSB(device))
+			walk(device.Path, indent+next)
+		}
+	}
+
+	// Root hubs have no parent and start a tree of their own
+	for _, root := range children[""] {
+		fmt.Fprintf(&out, "Bus %03d: %s\n", root.Bus, describeUSB(root))
+		walk(root.Path, "")
+	}
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// describeUSB summarises a device as name, IDs, class, speed, power and drivers
+func describeUSB(device helper.USBDeviceInfo) string {
+	parts := []string{fmt.Sprintf("ID %s:%s", device.VendorID, device.ProductID)}
+	if name := strings.TrimSpace(device.Vendor + " " + device.Name); name != "" {
+		parts = append(parts, name)
+	}
+
+	var details []string
+	if device.Class != "" {
+		details = append(details, device.Class)
+	}
+	if device.SpeedMbps > 0 {
+		details = append(details, formatUSBSpeed(device.SpeedMbps))
+	}
+	if device.MaxPowerMA > 0 {
+		details = append(details, fmt.Sprintf("%dmA", device.MaxPowerMA))
+	}
+	if len(device.Drivers) > 0 {
+		details = append(details, "driver="+strings.Join(device.Drivers, ","))
+	}
+	if device.Serial != "" {
+		details = append(details, "serial="+device.Serial)
+	}
+	if len(details) > 0 {
+		parts = append(parts, "("+strings.Join(details, ", ")+")")
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// formatUSBSpeed writes a link speed like lsusb -t, e.g. 480M or 5000M
+func formatUSBSpeed(mbps float64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.1f", mbps), "0"), ".") + "M"
+}